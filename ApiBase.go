@@ -2,7 +2,10 @@ package feishuapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -10,138 +13,270 @@ import (
 	"strings"
 	"time"
 
-	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
 )
 
+// The base urls of the open platform
+const (
+	FeishuBaseURL = "https://open.feishu.cn"
+	LarkBaseURL   = "https://open.larksuite.com"
+)
+
 type Config struct {
 	AppId             string
 	AppSecret         string
 	VerificationToken string
 	EncryptKey        string
+	// The open platform the requests are sent to, FeishuBaseURL if it is empty.
+	// It can also point to a mock server in tests
+	BaseURL string
 }
 
 type AppClient struct {
-	_tenant_access_token string
-	schedular            *cron.Cron
-	Conf                 Config
+	Conf Config
+
+	// Supply the tenant_access_token, nil means a TenantTokenManager shared by the clients with the same Conf
+	TokenProvider TenantTokenProvider
+	// Share the tenant_access_token with the clients in other processes, used when TokenProvider is nil
+	TokenStore TokenStore
+
+	// Retry the failed requests according to the policy, nil means every request is sent only once
+	RetryPolicy *RetryPolicy
+	// Throttle the outgoing requests on the client side, nil means no limit
+	RateLimiter *RateLimiter
+
+	// Send the requests with it, nil means a shared client with 15s timeout
+	HTTPClient *http.Client
+	// Wrap every outbound request, the first one is the outermost
+	Middlewares []Middleware
+}
+
+func (c AppClient) baseURL() string {
+	if c.Conf.BaseURL == "" {
+		return FeishuBaseURL
+	}
+	return strings.TrimRight(c.Conf.BaseURL, "/")
 }
 
 func (c AppClient) url(path string) string {
-	return "https://open.feishu.cn" + "/" + strings.Trim(path, "/")
+	return c.baseURL() + "/" + strings.Trim(path, "/")
 }
 
-func (c *AppClient) authorizeTenantAccessToken() bool {
-	u := c.url("/open-apis/auth/v3/tenant_access_token/internal")
+const (
+	tenantTokenPath = "open-apis/auth/v3/tenant_access_token/internal"
+	// authenticated by app_id and app_secret in the body
+	webSocketEndpointPath = "callback/ws/endpoint"
+)
 
-	urlValues := url.Values{}
-	urlValues.Add("app_id", c.Conf.AppId)
-	urlValues.Add("app_secret", c.Conf.AppSecret)
+// Check whether the request is sent without tenant_access_token
+func isTokenlessPath(path string) bool {
+	path = strings.Trim(path, "/")
+	return path == tenantTokenPath || path == webSocketEndpointPath
+}
 
-	resp, err := http.PostForm(u, urlValues)
+// Get the tenant_access_token in advance.
+//
+// Deprecated: the token is fetched on demand and refreshed before it expires, there's no need to call it any more
+func (c *AppClient) StartTokenTimer() {
+	if _, err := c.tenantTokenProvider().TenantAccessToken(context.Background()); err != nil {
+		logrus.WithError(err).Error("cannot get feishu token")
+	}
+}
 
+// Send a GET / POST / DELETE string to a specific path, with header of authorization and content-type
+// (in other words, authorization and content-type should not to be passed)
+// On the other hand, if the api needs user_access_token, then you can pass it by headers param
+func (c AppClient) Request(method string, path string, query map[string]any, headers map[string]string, body any) map[string]any {
+	data, err := c.RequestWithError(method, path, query, headers, body)
 	if err != nil {
+		jsonBody, _ := json.Marshal(body)
 		logrus.WithFields(logrus.Fields{
-			"response": resp,
-			"error":    err,
-		}).Error("cannot get tenant_access_token")
-		return false
+			"url":     c.url(path),
+			"query":   query,
+			"headers": headers,
+			"data":    string(jsonBody),
+			"error":   err,
+		}).Error("request error")
+		return nil
 	}
+	return data
+}
 
-	defer resp.Body.Close()
-
-	var result map[string]any
+// Same as Request, but report the failure as an error instead of logging it.
+// Errors reported by Feishu are returned as *APIError.
+// The values of query can be string, int, float64 or a slice of them, a value of another type
+// is reported as an error instead of being dropped from the url
+func (c AppClient) RequestWithError(method string, path string, query map[string]any, headers map[string]string, body any) (map[string]any, error) {
+	return c.RequestWithContext(context.Background(), method, path, query, headers, body)
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+// Same as RequestWithError, the request is bound to ctx so that it can be cancelled or carry a deadline
+func (c AppClient) RequestWithContext(ctx context.Context, method string, path string, query map[string]any, headers map[string]string, body any) (map[string]any, error) {
+	result, err := c.requestEnvelope(ctx, method, path, query, headers, body)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"response": resp,
-			"error":    err,
-		}).Error("cannot get tenant_access_token")
-		return false
+		return nil, err
 	}
-	json.Unmarshal(body, &result)
 
-	c._tenant_access_token = result["tenant_access_token"].(string)
-	logrus.Info("got tenant_access_token: " + c._tenant_access_token)
-	return true
+	data, ok := result["data"].(map[string]any)
+	if !ok {
+		data = make(map[string]any)
+	}
+	return data, nil
 }
 
-// Start a schedular to get tenant_access_token every 105 minutes
-func (c *AppClient) StartTokenTimer() {
-	if !c.authorizeTenantAccessToken() {
-		logrus.Error("cannot get feishu token")
+// Send a request and return the whole decoded response, for the apis which don't wrap the result in "data"
+func (c AppClient) requestEnvelope(ctx context.Context, method string, path string, query map[string]any, headers map[string]string, body any) (map[string]any, error) {
+	resp, respBody, err := c.send(ctx, method, path, query, headers, body)
+	if err != nil {
+		return nil, err
 	}
-
-	c.schedular = cron.New()
-	c.schedular.AddFunc("@every 105m", func() {
-		if !c.authorizeTenantAccessToken() {
-			logrus.Error("cannot get feishu token")
-		}
-	})
-
-	c.schedular.Start()
+	return parseResponse(method, path, resp, respBody)
 }
 
-func responseOk(resp *http.Response) bool {
-	if resp.StatusCode != 200 {
-		return false
+// Send a request for a binary resource, such as a file, and return the response with its body.
+// Feishu answers in JSON only when the request fails
+func (c AppClient) requestBinary(ctx context.Context, method string, path string, query map[string]any, headers map[string]string) (*http.Response, []byte, error) {
+	resp, respBody, err := c.send(ctx, method, path, query, headers, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusOK && !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return resp, respBody, nil
 	}
+	if _, err := parseResponse(method, path, resp, respBody); err != nil {
+		return nil, nil, err
+	}
+	return resp, respBody, nil
+}
 
-	var result map[string]any
+// Send a request and return the raw response body without checking the response code.
+// The request is throttled by c.RateLimiter and retried according to c.RetryPolicy
+func (c AppClient) send(ctx context.Context, method string, path string, query map[string]any, headers map[string]string, body any) (*http.Response, []byte, error) {
+	tokenRenewed := false
+	for attempt := 1; ; attempt++ {
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx, method, path); err != nil {
+				return nil, nil, err
+			}
+		}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		resp, respBody, tenantToken, err := c.sendOnce(ctx, method, path, query, headers, body)
+		if ctx.Err() != nil {
+			return resp, respBody, err
+		}
 
-	if err != nil {
+		// the tenant_access_token may be revoked before it expires, get a new one and try again
+		if tenantToken != "" && !tokenRenewed && err == nil && isInvalidTokenResponse(respBody) {
+			c.tenantTokenProvider().InvalidateTenantAccessToken(tenantToken)
+			tokenRenewed = true
+			attempt--
+			continue
+		}
+
+		delay, retry := c.RetryPolicy.backoff(attempt, method, resp, respBody, err)
+		if !retry {
+			return resp, respBody, err
+		}
 		logrus.WithFields(logrus.Fields{
-			"response": resp,
-			"error":    err,
-		}).Error("read response body error")
-		return false
+			"url":     c.url(path),
+			"attempt": attempt,
+			"delay":   delay,
+			"error":   err,
+		}).Warn("request failed, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Send the request exactly once, return the tenant_access_token if it is attached by the client
+func (c AppClient) sendOnce(ctx context.Context, method string, path string, query map[string]any, headers map[string]string, body any) (*http.Response, []byte, string, error) {
+	req, err := c.newRequest(ctx, method, path, query, headers, body)
+	if err != nil {
+		return nil, nil, "", err
 	}
-	json.Unmarshal(body, &result)
 
-	interface_code, ok := result["code"]
-	if !ok {
-		logrus.WithField("response", resp).Error("read response code error")
-		return false
+	tenantToken := ""
+	if req.Header.Get("Authorization") == "" && !isTokenlessPath(path) {
+		tenantToken, err = c.tenantTokenProvider().TenantAccessToken(ctx)
+		if err != nil {
+			return nil, nil, "", &tenantTokenError{err: err}
+		}
+		req.Header.Set("Authorization", "Bearer "+tenantToken)
 	}
 
-	code := interface_code.(float64)
+	resp, err := c.roundTrip()(req)
+	if err != nil {
+		return nil, nil, tenantToken, fmt.Errorf("%s %s: %w", req.Method, path, err)
+	}
+	defer resp.Body.Close()
 
-	return code == 0
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, tenantToken, fmt.Errorf("%s %s: read response body: %w", req.Method, path, err)
+	}
+	return resp, respBody, tenantToken, nil
 }
 
-// Send a GET / POST / DELETE string to a specific path, with header of authorization and content-type
-// (in other words, authorization and content-type should not to be passed)
-// On the other hand, if the api needs user_access_token, then you can pass it by headers param
-func (c AppClient) Request(method string, path string, query map[string]any, headers map[string]string, body any) map[string]any {
-	u := c.url(path)
+func (c AppClient) newRequest(ctx context.Context, method string, path string, query map[string]any, headers map[string]string, body any) (*http.Request, error) {
+	method = strings.ToUpper(method)
 
-	header := make(map[string]string, len(headers))
-	for k, v := range headers {
-		header[k] = v
+	Url, err := url.Parse(c.url(path))
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
 	}
 
-	if _, ok := header["Authorization"]; !ok {
-		header["Authorization"] = "Bearer " + c._tenant_access_token
+	urlValues, err := encodeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	// keep the query which is written in path directly
+	for k, v := range Url.Query() {
+		for _, s := range v {
+			urlValues.Add(k, s)
+		}
 	}
-	header["Content-Type"] = "application/json; charset=utf-8"
+	Url.RawQuery = urlValues.Encode()
 
 	var req *http.Request
-
-	urlValues := url.Values{}
-	Url, err := url.Parse(u)
+	contentType := "application/json; charset=utf-8"
+	if method == "GET" {
+		req, err = http.NewRequestWithContext(ctx, method, Url.String(), nil)
+	} else if form, ok := body.(*multipartBody); ok {
+		// the form is encoded again for every attempt
+		var bytesData []byte
+		bytesData, contentType, err = form.encode()
+		if err != nil {
+			return nil, fmt.Errorf("encode multipart body: %w", err)
+		}
+		req, err = http.NewRequestWithContext(ctx, method, Url.String(), bytes.NewReader(bytesData))
+	} else {
+		bytesData, merr := json.Marshal(body)
+		if merr != nil {
+			return nil, fmt.Errorf("marshal body: %w", merr)
+		}
+		req, err = http.NewRequestWithContext(ctx, method, Url.String(), bytes.NewReader(bytesData))
+	}
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"url":   u,
-			"query": query,
-			"err":   err,
-		}).Error("url parse error")
-		return nil
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return req, nil
+}
+
+// Encode the query of a request, a value of an unsupported type is an error
+func encodeQuery(query map[string]any) (url.Values, error) {
+	urlValues := url.Values{}
 	for k, v := range query {
 		switch vv := v.(type) {
 		case string:
@@ -163,155 +298,129 @@ func (c AppClient) Request(method string, path string, query map[string]any, hea
 				urlValues.Add(k, strconv.FormatFloat(s, 'f', -1, 64))
 			}
 		default:
-			logrus.WithFields(logrus.Fields{
-				"url":   u,
-				"query": query,
-			}).Error("query type error")
+			return nil, fmt.Errorf("query %s has unsupported type %T", k, v)
 		}
 	}
+	return urlValues, nil
+}
 
-	Url.RawQuery = urlValues.Encode()
-	urlPath := Url.String()
-
-	if strings.EqualFold(method, "get") {
-		req, err = http.NewRequest("GET", urlPath, nil)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"url":   u,
-				"query": query,
-				"err":   err,
-			}).Error("Request create error")
-			return nil
-		}
-
-	} else {
-		bytesData, err := json.Marshal(body)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"url":   u,
-				"query": query,
-				"err":   err,
-			}).Error("marshal query error")
-			return nil
-		}
-
-		req, err = http.NewRequest(strings.ToUpper(method), urlPath, bytes.NewReader(bytesData))
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"url":   u,
-				"query": query,
-				"err":   err,
-			}).Error("Request create error")
-			return nil
-		}
+// Decode the response body, turn a non-zero code or an unexpected http status into *APIError
+func parseResponse(method string, path string, resp *http.Response, body []byte) (map[string]any, error) {
+	apiErr := &APIError{
+		Method:     strings.ToUpper(method),
+		Path:       path,
+		HTTPStatus: resp.StatusCode,
+		LogId:      resp.Header.Get("X-Tt-Logid"),
 	}
 
-	for k, v := range header {
-		req.Header.Add(k, v)
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			apiErr.Msg = strings.TrimSpace(string(body))
+			return nil, apiErr
+		}
+		return nil, fmt.Errorf("%s %s: decode response: %w", apiErr.Method, path, err)
 	}
 
-	cli := &http.Client{
-		Timeout: time.Second * 15,
+	code, ok := result["code"].(float64)
+	if !ok && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("%s %s: response has no code", apiErr.Method, path)
 	}
-
-	resp, err := cli.Do(req)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"url":   u,
-			"query": query,
-			"err":   err,
-		}).Error("client do error")
-		return nil
+	if resp.StatusCode == http.StatusOK && code == 0 {
+		return result, nil
 	}
-	defer resp.Body.Close()
 
-	if !responseOk(resp) {
-		jsonBody, _ := json.Marshal(body)
-		respBody, _ := ioutil.ReadAll(resp.Body)
-		logrus.WithFields(logrus.Fields{
-			"url":      u,
-			"query":    query,
-			"headers":  headers,
-			"data":     jsonBody,
-			"error":    "response incorrect",
-			"response": string(respBody),
-		}).Error("response status error")
-		return nil
+	apiErr.Code = int(code)
+	apiErr.Msg, _ = result["msg"].(string)
+	if detail, ok := result["error"].(map[string]any); ok && apiErr.LogId == "" {
+		apiErr.LogId, _ = detail["log_id"].(string)
 	}
+	return nil, apiErr
+}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
-
+// Send Request several times until all the pages of information are got
+func (c AppClient) GetAllPages(method string, path string, query map[string]any, headers map[string]string, body any, page_size int) []any {
+	all_list, err := c.GetAllPagesWithError(method, path, query, headers, body, page_size)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
-			"url":   u,
+			"url":   c.url(path),
 			"query": query,
-			"err":   err,
-		}).Error("read response body error")
+			"error": err,
+		}).Error("get all pages error")
 		return nil
 	}
+	return all_list
+}
 
-	var result map[string]any
-
-	json.Unmarshal(respBody, &result)
+// Same as GetAllPages, but report the failure as an error instead of logging it
+func (c AppClient) GetAllPagesWithError(method string, path string, query map[string]any, headers map[string]string, body any, page_size int) ([]any, error) {
+	return c.GetAllPagesWithContext(context.Background(), method, path, query, headers, body, page_size)
+}
 
-	return result["data"].(map[string]any)
+// Same as GetAllPagesWithError, the walk stops as soon as ctx is done
+func (c AppClient) GetAllPagesWithContext(ctx context.Context, method string, path string, query map[string]any, headers map[string]string, body any, page_size int) ([]any, error) {
+	return c.getAllPages(ctx, method, path, query, headers, body, page_size, "items")
 }
 
-// Send Request several times until all the pages of information are got
-func (c AppClient) GetAllPages(method string, path string, query map[string]any, headers map[string]string, body any, page_size int) []any {
+// Walk through all the pages, collecting the list under itemsKey of every page
+func (c AppClient) getAllPages(ctx context.Context, method string, path string, query map[string]any, headers map[string]string, body any, page_size int, itemsKey string) ([]any, error) {
 	if page_size < 10 || page_size > 100 {
-		logrus.Info("page_size should be between 10 and 100")
-		return nil
+		return nil, errors.New("page_size should be between 10 and 100")
 	}
 
-	var all_list []any
+	all_list := []any{}
 	page_token := ""
 	has_more := true
 	queries := make(map[string]any)
-	if len(query) != 0 {
-		for k, v := range query {
-			queries[k] = v
-		}
+	for k, v := range query {
+		queries[k] = v
 	}
 	queries["page_size"] = strconv.Itoa(page_size)
 
-	for {
-		if !has_more {
-			break
+	for has_more {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 		if page_token != "" {
 			queries["page_token"] = page_token
 		}
 
-		resp := c.Request(method, path, queries, headers, body)
-		if len(resp) == 0 {
-			return nil
-		}
-		if resp["items"] == nil {
-			return []any{}
+		resp, err := c.RequestWithContext(ctx, method, path, queries, headers, body)
+		if err != nil {
+			return nil, err
 		}
 
-		l := resp["items"].([]any)
-		has_more = resp["has_more"].(bool)
-		interface_page_token, ok := resp["page_token"]
-		if ok {
-			page_token = interface_page_token.(string)
-		} else {
-			page_token = ""
+		l, _ := resp[itemsKey].([]any)
+		has_more, _ = resp["has_more"].(bool)
+		page_token, _ = resp["page_token"].(string)
+		if page_token == "" {
+			has_more = false
 		}
 
 		all_list = append(all_list, l...)
 	}
 
-	return all_list
+	return all_list, nil
 }
 
 // Get the value of provided key in a map, if there's no such key than return provided defaults
 func getInMap(mapToSearch map[string]any, key string, defaults any) any {
 	value, ok := mapToSearch[key]
-	if !ok {
+	if !ok || value == nil {
 		return defaults
 	} else {
 		return value
 	}
 }
+
+// Get the list of strings under the key in a map, the values which aren't strings are skipped
+func getStringsInMap(mapToSearch map[string]any, key string) []string {
+	l, _ := mapToSearch[key].([]any)
+	values := []string{}
+	for _, v := range l {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
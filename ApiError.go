@@ -0,0 +1,127 @@
+package feishuapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Feishu response codes that the error helpers below know about.
+// The full list can be found at https://open.feishu.cn/document/server-docs/api-call-guide/generic-error-code
+const (
+	CodeRateLimited           = 99991400
+	CodeInvalidTenantToken    = 99991663
+	CodeInvalidUserToken      = 99991668
+	CodeAppScopeNotEnabled    = 99991672
+	CodeUserScopeUnauthorized = 99991679
+	CodeNoPermission          = 1254302
+	CodeChatNotFound          = 232010
+	CodeRecordNotFound        = 1254043
+	CodeTableNotFound         = 1254041
+	CodeWikiNodeNotFound      = 131005
+)
+
+// APIError is returned by the error-returning request path when Feishu answers
+// with a non-zero code or an unexpected HTTP status.
+type APIError struct {
+	Method     string
+	Path       string
+	HTTPStatus int
+	Code       int
+	Msg        string
+	LogId      string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: code=%d msg=%q http_status=%d log_id=%s",
+		e.Method, e.Path, e.Code, e.Msg, e.HTTPStatus, e.LogId)
+}
+
+// Check whether err is an APIError caused by Feishu rate limiting
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.HTTPStatus == http.StatusTooManyRequests || apiErr.Code == CodeRateLimited
+}
+
+// Check whether err is an APIError caused by missing app scopes or resource permissions
+func IsPermissionDenied(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case CodeAppScopeNotEnabled, CodeUserScopeUnauthorized, CodeNoPermission:
+		return true
+	}
+	return apiErr.HTTPStatus == http.StatusForbidden
+}
+
+// Check whether err is an APIError caused by a missing resource
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case CodeChatNotFound, CodeRecordNotFound, CodeTableNotFound, CodeWikiNodeNotFound:
+		return true
+	}
+	return apiErr.HTTPStatus == http.StatusNotFound
+}
+
+// Check whether the response body reports that the access token is invalid
+func isInvalidTokenResponse(body []byte) bool {
+	var result struct {
+		Code int `json:"code"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return false
+	}
+	return result.Code == CodeInvalidTenantToken || result.Code == CodeInvalidUserToken
+}
+
+// multiError collects the errors of several requests which are issued by one api call
+type multiError []error
+
+func (m multiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Let errors.As / errors.Is look into every joined error
+func (m multiError) As(target any) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Join the errors together, return nil if there is no error
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return multiError(errs)
+}
@@ -0,0 +1,83 @@
+package feishuapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RoundTripFunc sends one http request, it is the unit which the middlewares wrap
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps every outbound http request of AppClient, each retry is wrapped separately
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// used when AppClient.HTTPClient is nil, shared so that the connections are reused
+var defaultHTTPClient = &http.Client{
+	Timeout: time.Second * 15,
+}
+
+func (c AppClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
+// Build the round trip of the request, the first middleware is the outermost one
+func (c AppClient) roundTrip() RoundTripFunc {
+	rt := RoundTripFunc(c.httpClient().Do)
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		rt = c.Middlewares[i](rt)
+	}
+	return rt
+}
+
+// Log every request with its status and latency at debug level, and the failed ones at warn level
+func LoggingMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+
+			entry := logrus.WithFields(logrus.Fields{
+				"method":  req.Method,
+				"url":     req.URL.String(),
+				"latency": time.Since(start),
+			})
+			if err != nil {
+				entry.WithError(err).Warn("feishu request fail")
+				return resp, err
+			}
+			entry.WithField("status", resp.StatusCode).Debug("feishu request")
+			return resp, err
+		}
+	}
+}
+
+// Set the headers on every request, the headers which are already set are kept
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			for k, v := range headers {
+				if req.Header.Get(k) == "" {
+					req.Header.Set(k, v)
+				}
+			}
+			return next(req)
+		}
+	}
+}
+
+// Report how long every request takes until the response header arrives, resp is nil if err isn't
+func LatencyMiddleware(observe func(req *http.Request, resp *http.Response, err error, latency time.Duration)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			observe(req, resp, err, time.Since(start))
+			return resp, err
+		}
+	}
+}
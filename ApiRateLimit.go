@@ -0,0 +1,127 @@
+package feishuapi
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a client side token bucket limiter.
+// Requests are limited by the bucket of the longest endpoint prefix they match,
+// the requests which match no endpoint share the default bucket
+type RateLimiter struct {
+	mu        sync.Mutex
+	defaults  *tokenBucket
+	endpoints map[string]*tokenBucket
+}
+
+// Create a RateLimiter, which allows rate requests per second with bursts of at most burst requests by default.
+// A rate <= 0 means the requests which match no endpoint are not limited
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	limiter := &RateLimiter{
+		endpoints: make(map[string]*tokenBucket),
+	}
+	if rate > 0 {
+		limiter.defaults = newTokenBucket(rate, burst)
+	}
+	return limiter
+}
+
+// Set a separate limit for the requests whose path starts with pathPrefix, e.g. "open-apis/bitable/v1/apps".
+// An empty method matches all the methods
+func (l *RateLimiter) WithEndpointLimit(method string, pathPrefix string, rate float64, burst int) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.endpoints[endpointKey(method, pathPrefix)] = newTokenBucket(rate, burst)
+	return l
+}
+
+// Block until the request is allowed to be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, method string, path string) error {
+	for {
+		l.mu.Lock()
+		bucket := l.bucket(method, path)
+		var delay time.Duration
+		if bucket != nil {
+			delay = bucket.take(time.Now())
+		}
+		l.mu.Unlock()
+
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *RateLimiter) bucket(method string, path string) *tokenBucket {
+	path = strings.Trim(path, "/")
+	method = strings.ToUpper(method)
+
+	var matched *tokenBucket
+	matchedLen := -1
+	for key, bucket := range l.endpoints {
+		keyMethod, prefix, _ := strings.Cut(key, " ")
+		if keyMethod != "" && keyMethod != method {
+			continue
+		}
+		if strings.HasPrefix(path, prefix) && len(prefix) > matchedLen {
+			matched = bucket
+			matchedLen = len(prefix)
+		}
+	}
+	if matched != nil {
+		return matched
+	}
+	return l.defaults
+}
+
+func endpointKey(method string, pathPrefix string) string {
+	return strings.ToUpper(method) + " " + strings.Trim(pathPrefix, "/")
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Take a token if there is one, otherwise return how long to wait for the next token
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
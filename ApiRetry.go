@@ -0,0 +1,145 @@
+package feishuapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether and when a failed request is sent again.
+// Requests are retried on network errors, HTTP 429, transient 5xx and the Feishu rate limit code,
+// with exponential backoff and jitter. When Feishu tells when the rate limit resets, that time is waited instead
+type RetryPolicy struct {
+	// Total number of attempts, including the first one
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Also retry POST / PATCH requests, which may be applied twice by Feishu
+	RetryNonIdempotent bool
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:        3,
+		BaseDelay:          time.Millisecond * 500,
+		MaxDelay:           time.Second * 30,
+		RetryNonIdempotent: false,
+	}
+}
+
+func (p *RetryPolicy) WithMaxAttempts(maxAttempts int) *RetryPolicy {
+	p.MaxAttempts = maxAttempts
+	return p
+}
+
+func (p *RetryPolicy) WithBaseDelay(baseDelay time.Duration) *RetryPolicy {
+	p.BaseDelay = baseDelay
+	return p
+}
+
+func (p *RetryPolicy) WithMaxDelay(maxDelay time.Duration) *RetryPolicy {
+	p.MaxDelay = maxDelay
+	return p
+}
+
+func (p *RetryPolicy) WithRetryNonIdempotent(retryNonIdempotent bool) *RetryPolicy {
+	p.RetryNonIdempotent = retryNonIdempotent
+	return p
+}
+
+// Decide whether the attempt-th attempt should be retried and how long to wait before that
+func (p *RetryPolicy) backoff(attempt int, method string, resp *http.Response, body []byte, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if !p.RetryNonIdempotent && !isIdempotent(method) {
+		return 0, false
+	}
+	if err != nil && !isTransportError(err) {
+		return 0, false
+	}
+	if err == nil && !isTransientResponse(resp, body) {
+		return 0, false
+	}
+
+	// a reset of 0 means the limit has already reset, which is no reason to retry at once, so back off as usual
+	if resp != nil {
+		if reset, perr := strconv.Atoi(resp.Header.Get("x-ogw-ratelimit-reset")); perr == nil && reset > 0 {
+			delay := time.Duration(reset) * time.Second
+			if p.MaxDelay > 0 && delay > p.MaxDelay {
+				delay = p.MaxDelay
+			}
+			return delay, true
+		}
+	}
+
+	delay := p.BaseDelay << (attempt - 1)
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+	// full jitter in the upper half, so that the clients which are throttled together don't come back together
+	if delay > 1 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+	return delay, true
+}
+
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// tenantTokenError is the failure to get the tenant_access_token of a request, the fetch is retried by itself
+type tenantTokenError struct {
+	err error
+}
+
+func (e *tenantTokenError) Error() string {
+	return e.err.Error()
+}
+
+func (e *tenantTokenError) Unwrap() error {
+	return e.err
+}
+
+// Check whether the request failed on the connection, which may succeed when it is sent again.
+// The errors before sending, such as a body which can't be marshaled, fail the same way every time
+func isTransportError(err error) bool {
+	var tokenErr *tenantTokenError
+	if errors.As(err, &tokenErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// Check whether the response is a rate limit or a transient server error
+func isTransientResponse(resp *http.Response, body []byte) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	var result struct {
+		Code int `json:"code"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return false
+	}
+	return result.Code == CodeRateLimited
+}
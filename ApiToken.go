@@ -0,0 +1,276 @@
+package feishuapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TenantTokenProvider supplies the tenant_access_token which is attached to the requests of AppClient
+type TenantTokenProvider interface {
+	TenantAccessToken(ctx context.Context) (string, error)
+	// Drop the token which is rejected by Feishu, so that the next call fetches a new one
+	InvalidateTenantAccessToken(token string)
+}
+
+// TenantTokenFetcher gets a new tenant_access_token and how long it is valid
+type TenantTokenFetcher func(ctx context.Context) (token string, expire time.Duration, err error)
+
+const (
+	defaultTokenRefreshAhead = time.Minute * 5
+	tokenFetchTimeout        = time.Second * 30
+)
+
+// TenantTokenManager is the default TenantTokenProvider.
+// The token is fetched on demand and refreshed before it expires,
+// concurrent refreshes are deduplicated so that only one request is sent to Feishu at a time
+type TenantTokenManager struct {
+	fetch        TenantTokenFetcher
+	refreshAhead time.Duration
+	store        TokenStore
+	storeKey     string
+
+	mu        sync.Mutex
+	token     string
+	expireAt  time.Time
+	refreshAt time.Time
+	inflight  *tokenFetch
+}
+
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func NewTenantTokenManager(fetch TenantTokenFetcher) *TenantTokenManager {
+	return &TenantTokenManager{
+		fetch:        fetch,
+		refreshAhead: defaultTokenRefreshAhead,
+	}
+}
+
+// Refresh the token when it will expire within refreshAhead
+func (m *TenantTokenManager) WithRefreshAhead(refreshAhead time.Duration) *TenantTokenManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshAhead = refreshAhead
+	return m
+}
+
+// Share the token through store under key, so that the managers of other clients or processes can reuse it
+// instead of asking Feishu for their own
+func (m *TenantTokenManager) WithStore(store TokenStore, key string) *TenantTokenManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+	m.storeKey = key
+	return m
+}
+
+func (m *TenantTokenManager) TenantAccessToken(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	store, key := m.store, m.storeKey
+	m.mu.Unlock()
+	return m.tenantAccessToken(ctx, m.fetch, store, key)
+}
+
+// Get the token, a new one is obtained by fetch and shared through store if the cached one is going to expire
+func (m *TenantTokenManager) tenantAccessToken(ctx context.Context, fetch TenantTokenFetcher, store TokenStore, key string) (string, error) {
+	m.mu.Lock()
+	now := time.Now()
+	if m.token != "" && now.Before(m.refreshAt) {
+		token := m.token
+		m.mu.Unlock()
+		return token, nil
+	}
+
+	call := m.inflight
+	if call == nil {
+		call = &tokenFetch{done: make(chan struct{})}
+		m.inflight = call
+		go m.refresh(call, fetch, store, key)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-call.done:
+	}
+
+	if call.err == nil {
+		return call.token, nil
+	}
+
+	// keep using the old token while it is still valid
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token != "" && time.Now().Before(m.expireAt) {
+		logrus.WithError(call.err).Warn("refresh tenant_access_token fail, use the old one")
+		return m.token, nil
+	}
+	return "", call.err
+}
+
+func (m *TenantTokenManager) InvalidateTenantAccessToken(token string) {
+	m.mu.Lock()
+	store, key := m.store, m.storeKey
+	m.mu.Unlock()
+	m.invalidateTenantAccessToken(token, store, key)
+}
+
+func (m *TenantTokenManager) invalidateTenantAccessToken(token string, store TokenStore, key string) {
+	m.mu.Lock()
+	if m.token == token {
+		m.token = ""
+		m.expireAt = time.Time{}
+		m.refreshAt = time.Time{}
+	}
+	m.mu.Unlock()
+
+	if store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tokenFetchTimeout)
+		defer cancel()
+		if _, err := store.CompareAndSwap(ctx, key, token, "", 0); err != nil {
+			logrus.WithError(err).Warn("delete tenant_access_token from store fail")
+		}
+	}
+}
+
+// Get a new token without being bound to the ctx of any caller, since all the waiting callers share the result
+func (m *TenantTokenManager) refresh(call *tokenFetch, fetch TenantTokenFetcher, store TokenStore, key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenFetchTimeout)
+	defer cancel()
+
+	m.mu.Lock()
+	refreshAhead := m.refreshAhead
+	m.mu.Unlock()
+
+	token, expireAt, err := m.obtain(ctx, fetch, refreshAhead, store, key)
+
+	m.mu.Lock()
+	if err == nil {
+		now := time.Now()
+		m.token = token
+		m.expireAt = expireAt
+		if expireAt.Sub(now) > refreshAhead {
+			m.refreshAt = expireAt.Add(-refreshAhead)
+		} else {
+			m.refreshAt = now.Add(expireAt.Sub(now) / 2)
+		}
+	}
+	m.inflight = nil
+	m.mu.Unlock()
+
+	call.token, call.err = token, err
+	close(call.done)
+}
+
+// Take the token from the store if it isn't going to expire, otherwise fetch a new one and publish it to the store
+func (m *TenantTokenManager) obtain(ctx context.Context, fetch TenantTokenFetcher, refreshAhead time.Duration, store TokenStore, key string) (string, time.Time, error) {
+	old := ""
+	if store != nil {
+		value, expireAt, err := store.Get(ctx, key)
+		switch {
+		case err == nil && time.Until(expireAt) > refreshAhead:
+			return value, expireAt, nil
+		case err == nil:
+			old = value
+		case !errors.Is(err, ErrTokenNotFound):
+			logrus.WithError(err).Warn("read tenant_access_token from store fail")
+		}
+	}
+
+	fetchedAt := time.Now()
+	token, expire, err := fetch(ctx)
+	if err == nil && token == "" {
+		err = errors.New("empty tenant_access_token")
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if store != nil {
+		swapped, err := store.CompareAndSwap(ctx, key, old, token, expire)
+		if err != nil {
+			logrus.WithError(err).Warn("write tenant_access_token to store fail")
+		} else if !swapped {
+			// another client has refreshed the token at the same time, use the one in the store
+			if value, expireAt, err := store.Get(ctx, key); err == nil {
+				return value, expireAt, nil
+			}
+		}
+	}
+	return token, fetchedAt.Add(expire), nil
+}
+
+// the token managers which are shared by the AppClients with the same credentials
+var sharedTokenManagers sync.Map
+
+// Only the values which identify the token are in the key,
+// the HTTPClient, Middlewares, RetryPolicy and TokenStore of the client are applied per call by clientTenantTokenProvider
+type tokenManagerKey struct {
+	appId     string
+	appSecret string
+	baseURL   string
+}
+
+// clientTenantTokenProvider gets the token from the shared manager, but fetches and stores it through its own client
+type clientTenantTokenProvider struct {
+	manager *TenantTokenManager
+	client  AppClient
+}
+
+func (p clientTenantTokenProvider) TenantAccessToken(ctx context.Context) (string, error) {
+	store, key := p.client.tenantTokenStore()
+	return p.manager.tenantAccessToken(ctx, p.client.fetchTenantAccessToken, store, key)
+}
+
+func (p clientTenantTokenProvider) InvalidateTenantAccessToken(token string) {
+	store, key := p.client.tenantTokenStore()
+	p.manager.invalidateTenantAccessToken(token, store, key)
+}
+
+func (c AppClient) tenantTokenProvider() TenantTokenProvider {
+	if c.TokenProvider != nil {
+		return c.TokenProvider
+	}
+
+	key := tokenManagerKey{appId: c.Conf.AppId, appSecret: c.Conf.AppSecret, baseURL: c.baseURL()}
+	m, ok := sharedTokenManagers.Load(key)
+	if !ok {
+		m, _ = sharedTokenManagers.LoadOrStore(key, NewTenantTokenManager(c.fetchTenantAccessToken))
+	}
+	return clientTenantTokenProvider{manager: m.(*TenantTokenManager), client: c}
+}
+
+func (c AppClient) tenantTokenStore() (TokenStore, string) {
+	if c.TokenStore == nil {
+		return nil, ""
+	}
+	return c.TokenStore, "feishuapi:tenant_access_token:" + c.Conf.AppId
+}
+
+func (c AppClient) fetchTenantAccessToken(ctx context.Context) (string, time.Duration, error) {
+	body := make(map[string]string)
+	body["app_id"] = c.Conf.AppId
+	body["app_secret"] = c.Conf.AppSecret
+
+	result, err := c.requestEnvelope(ctx, "post", tenantTokenPath, nil, nil, body)
+	if err != nil {
+		return "", 0, fmt.Errorf("get tenant_access_token: %w", err)
+	}
+
+	token, _ := result["tenant_access_token"].(string)
+	expire, _ := result["expire"].(float64)
+	if token == "" {
+		return "", 0, errors.New("tenant_access_token is missing in response")
+	}
+	logrus.Debug("got tenant_access_token, expire in ", expire, " seconds")
+	return token, time.Duration(expire) * time.Second, nil
+}
@@ -0,0 +1,210 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrTokenNotFound is returned by TokenStore.Get when there is no unexpired value for the key
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore keeps the tokens which are shared by several AppClients, in one process or across replicas
+type TokenStore interface {
+	// Get the value of key and the time it expires at, ErrTokenNotFound if it is absent or expired
+	Get(ctx context.Context, key string) (value string, expireAt time.Time, err error)
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	// Set key to value only if its current value is old, where "" means absent or expired.
+	// Setting value to "" deletes the key. Report whether the value is swapped
+	CompareAndSwap(ctx context.Context, key string, old string, value string, ttl time.Duration) (bool, error)
+}
+
+type storedToken struct {
+	Value    string    `json:"value"`
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+// Apply a compare-and-swap on the tokens, report whether the value is swapped
+func compareAndSwapToken(tokens map[string]storedToken, key string, old string, value string, ttl time.Duration, now time.Time) bool {
+	current := ""
+	if token, ok := tokens[key]; ok && now.Before(token.ExpireAt) {
+		current = token.Value
+	}
+	if current != old {
+		return false
+	}
+
+	if value == "" {
+		delete(tokens, key)
+	} else {
+		tokens[key] = storedToken{Value: value, ExpireAt: now.Add(ttl)}
+	}
+	return true
+}
+
+func getToken(tokens map[string]storedToken, key string, now time.Time) (string, time.Time, error) {
+	token, ok := tokens[key]
+	if !ok || !now.Before(token.ExpireAt) {
+		return "", time.Time{}, ErrTokenNotFound
+	}
+	return token.Value, token.ExpireAt, nil
+}
+
+// MemoryTokenStore shares tokens between the AppClients in one process
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]storedToken
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]storedToken),
+	}
+}
+
+func (s *MemoryTokenStore) Get(ctx context.Context, key string) (string, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return getToken(s.tokens, key, time.Now())
+}
+
+func (s *MemoryTokenStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = storedToken{Value: value, ExpireAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryTokenStore) CompareAndSwap(ctx context.Context, key string, old string, value string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return compareAndSwapToken(s.tokens, key, old, value, ttl, time.Now()), nil
+}
+
+const (
+	fileLockRetryInterval = time.Millisecond * 20
+	// a lock file older than this is left by a crashed process
+	fileLockStaleAfter = time.Second * 10
+)
+
+// FileTokenStore keeps tokens in a JSON file, so that the processes on one host or a shared volume can share them.
+// Writes are guarded by a lock file next to it, and the file is replaced atomically
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (s *FileTokenStore) Get(ctx context.Context, key string) (string, time.Time, error) {
+	var value string
+	var expireAt time.Time
+	var getErr error
+	err := s.withLock(ctx, func(tokens map[string]storedToken) bool {
+		value, expireAt, getErr = getToken(tokens, key, time.Now())
+		return false
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return value, expireAt, getErr
+}
+
+func (s *FileTokenStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return s.withLock(ctx, func(tokens map[string]storedToken) bool {
+		tokens[key] = storedToken{Value: value, ExpireAt: time.Now().Add(ttl)}
+		return true
+	})
+}
+
+func (s *FileTokenStore) CompareAndSwap(ctx context.Context, key string, old string, value string, ttl time.Duration) (bool, error) {
+	swapped := false
+	err := s.withLock(ctx, func(tokens map[string]storedToken) bool {
+		swapped = compareAndSwapToken(tokens, key, old, value, ttl, time.Now())
+		return swapped
+	})
+	return swapped, err
+}
+
+// Load the tokens under the lock, and write them back if fn reports a change
+func (s *FileTokenStore) withLock(ctx context.Context, fn func(tokens map[string]storedToken) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens := make(map[string]storedToken)
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read token file: %w", err)
+	}
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, &tokens); err != nil {
+			return fmt.Errorf("decode token file: %w", err)
+		}
+	}
+
+	if !fn(tokens) {
+		return nil
+	}
+
+	data, err = json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("write token file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write token file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write token file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileTokenStore) lock(ctx context.Context) (func(), error) {
+	lockPath := s.path + ".lock"
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("lock token file: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > fileLockStaleAfter {
+			os.Remove(lockPath)
+			continue
+		}
+
+		timer := time.NewTimer(fileLockRetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
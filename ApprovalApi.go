@@ -1,6 +1,7 @@
 package feishuapi
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
 	"time"
@@ -21,10 +22,22 @@ type ApprovalInstanceInfo struct {
 }
 
 func (c AppClient) ApprovalInstanceById(InstanceCode string) *ApprovalInstanceInfo {
-	resp := c.Request("get", "open-apis/approval/v4/instances/"+InstanceCode, nil, nil, nil)
-	if resp == nil {
+	info, err := c.ApprovalInstanceByIdWithError(InstanceCode)
+	if err != nil {
 		return nil
 	}
+	return info
+}
+
+func (c AppClient) ApprovalInstanceByIdWithError(InstanceCode string) (*ApprovalInstanceInfo, error) {
+	return c.ApprovalInstanceByIdWithContext(context.Background(), InstanceCode)
+}
+
+func (c AppClient) ApprovalInstanceByIdWithContext(ctx context.Context, InstanceCode string) (*ApprovalInstanceInfo, error) {
+	resp, err := c.RequestWithContext(ctx, "get", "open-apis/approval/v4/instances/"+InstanceCode, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
 	start_time, _ := strconv.ParseInt(resp["start_time"].(string), 10, 64)
 	end_time, _ := strconv.ParseInt(resp["end_time"].(string), 10, 64)
 	var timeline []TimelineNode
@@ -43,5 +56,5 @@ func (c AppClient) ApprovalInstanceById(InstanceCode string) *ApprovalInstanceIn
 		DepartmentId: resp["department_id"].(string),
 		Timeline:     timeline,
 		Form:         form,
-	}
+	}, nil
 }
@@ -1,5 +1,12 @@
 package feishuapi
 
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
 type CalendarPermission string
 
 const (
@@ -54,31 +61,92 @@ func NewCalendar(data map[string]any) *Calendar {
 }
 
 func (c AppClient) CalendarCreateByUser(calendar *CalendarCreateRequest, user_access_token string) *Calendar {
-	headers := make(map[string]string)
-	headers["Authorization"] = user_access_token
+	info, err := c.CalendarCreateByUserWithError(calendar, user_access_token)
+	if err != nil {
+		logrus.WithError(err).Error("create calendar fail")
+		return nil
+	}
+	return info
+}
 
-	body := make(map[string]any)
-	struct2map(calendar, &body)
+func (c AppClient) CalendarCreateByUserWithError(calendar *CalendarCreateRequest, user_access_token string) (*Calendar, error) {
+	return c.CalendarCreateByUserWithContext(context.Background(), calendar, StaticUserToken(user_access_token))
+}
 
-	info := c.Request("post", "open-apis/calendar/v4/calendars", nil, headers, body)
-	return NewCalendar(info["calendar"].(map[string]any))
+func (c AppClient) CalendarCreateByUserWithContext(ctx context.Context, calendar *CalendarCreateRequest, userToken UserToken) (*Calendar, error) {
+	headers, err := userAuthHeaders(ctx, userToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.calendarCreate(ctx, calendar, headers)
 }
 
 func (c AppClient) CalendarCreateByBot(calendar *CalendarCreateRequest) *Calendar {
+	info, err := c.CalendarCreateByBotWithError(calendar)
+	if err != nil {
+		logrus.WithError(err).Error("create calendar fail")
+		return nil
+	}
+	return info
+}
+
+func (c AppClient) CalendarCreateByBotWithError(calendar *CalendarCreateRequest) (*Calendar, error) {
+	return c.CalendarCreateByBotWithContext(context.Background(), calendar)
+}
+
+func (c AppClient) CalendarCreateByBotWithContext(ctx context.Context, calendar *CalendarCreateRequest) (*Calendar, error) {
+	return c.calendarCreate(ctx, calendar, nil)
+}
+
+func (c AppClient) calendarCreate(ctx context.Context, calendar *CalendarCreateRequest, headers map[string]string) (*Calendar, error) {
 	body := make(map[string]any)
-	struct2map(calendar, &body)
+	if err := struct2map(calendar, &body); err != nil {
+		return nil, err
+	}
 
-	info := c.Request("post", "open-apis/calendar/v4/calendars", nil, nil, body)
-	return NewCalendar(info["calendar"].(map[string]any))
+	info, err := c.RequestWithContext(ctx, "post", "open-apis/calendar/v4/calendars", nil, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := info["calendar"].(map[string]any)
+	if !ok {
+		return nil, errors.New("calendar is missing in response")
+	}
+	return NewCalendar(data), nil
 }
 
 func (c AppClient) CalendarSubscribeByUser(calendarId string, user_access_token string) {
-	headers := make(map[string]string)
-	headers["Authorization"] = user_access_token
+	if err := c.CalendarSubscribeByUserWithError(calendarId, user_access_token); err != nil {
+		logrus.WithField("CalendarID", calendarId).WithError(err).Error("subscribe calendar fail")
+	}
+}
 
-	c.Request("post", "open-apis/calendar/v4/calendars/"+calendarId+"/subscribe", nil, headers, nil)
+func (c AppClient) CalendarSubscribeByUserWithError(calendarId string, user_access_token string) error {
+	return c.CalendarSubscribeByUserWithContext(context.Background(), calendarId, StaticUserToken(user_access_token))
+}
+
+func (c AppClient) CalendarSubscribeByUserWithContext(ctx context.Context, calendarId string, userToken UserToken) error {
+	headers, err := userAuthHeaders(ctx, userToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.RequestWithContext(ctx, "post", "open-apis/calendar/v4/calendars/"+calendarId+"/subscribe", nil, headers, nil)
+	return err
 }
 
 func (c AppClient) CalendarSubscribeByBot(calendarId string) {
-	c.Request("post", "open-apis/calendar/v4/calendars/"+calendarId+"/subscribe", nil, nil, nil)
+	if err := c.CalendarSubscribeByBotWithError(calendarId); err != nil {
+		logrus.WithField("CalendarID", calendarId).WithError(err).Error("subscribe calendar fail")
+	}
+}
+
+func (c AppClient) CalendarSubscribeByBotWithError(calendarId string) error {
+	return c.CalendarSubscribeByBotWithContext(context.Background(), calendarId)
+}
+
+func (c AppClient) CalendarSubscribeByBotWithContext(ctx context.Context, calendarId string) error {
+	_, err := c.RequestWithContext(ctx, "post", "open-apis/calendar/v4/calendars/"+calendarId+"/subscribe", nil, nil, nil)
+	return err
 }
@@ -1,9 +1,13 @@
 package feishuapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"strconv"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 type TimeInfo struct {
@@ -129,28 +133,90 @@ func NewCalendarEvent(data map[string]any) *CalendarEvent {
 }
 
 func (c AppClient) CalendarEventCreate(calendarId string, calendarEvent *CalendarEventCreateRequest) *CalendarEvent {
+	event, err := c.CalendarEventCreateWithError(calendarId, calendarEvent)
+	if err != nil {
+		logrus.WithField("CalendarID", calendarId).WithError(err).Error("create calendar event fail")
+		return nil
+	}
+	return event
+}
+
+func (c AppClient) CalendarEventCreateWithError(calendarId string, calendarEvent *CalendarEventCreateRequest) (*CalendarEvent, error) {
+	return c.CalendarEventCreateWithContext(context.Background(), calendarId, calendarEvent)
+}
+
+func (c AppClient) CalendarEventCreateWithContext(ctx context.Context, calendarId string, calendarEvent *CalendarEventCreateRequest) (*CalendarEvent, error) {
 	body := make(map[string]any)
-	struct2map(calendarEvent, &body)
+	if err := struct2map(calendarEvent, &body); err != nil {
+		return nil, err
+	}
 
-	info := c.Request("post", "open-apis/calendar/v4/calendars/"+calendarId+"/events", nil, nil, body)
-	return NewCalendarEvent(info["event"].(map[string]any))
+	info, err := c.RequestWithContext(ctx, "post", "open-apis/calendar/v4/calendars/"+calendarId+"/events", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	return newCalendarEventFromResponse(info)
 }
 
 func (c AppClient) CalendarEventQuery(calendarId string, eventId string) *CalendarEvent {
-	info := c.Request("get", "open-apis/calendar/v4/calendars/"+calendarId+"/events/"+eventId, nil, nil, nil)
-	return NewCalendarEvent(info["event"].(map[string]any))
+	event, err := c.CalendarEventQueryWithError(calendarId, eventId)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"CalendarID": calendarId,
+			"EventID":    eventId,
+			"error":      err,
+		}).Warn("nil calendar event return")
+		return nil
+	}
+	return event
+}
+
+func (c AppClient) CalendarEventQueryWithError(calendarId string, eventId string) (*CalendarEvent, error) {
+	return c.CalendarEventQueryWithContext(context.Background(), calendarId, eventId)
+}
+
+func (c AppClient) CalendarEventQueryWithContext(ctx context.Context, calendarId string, eventId string) (*CalendarEvent, error) {
+	info, err := c.RequestWithContext(ctx, "get", "open-apis/calendar/v4/calendars/"+calendarId+"/events/"+eventId, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newCalendarEventFromResponse(info)
+}
+
+func newCalendarEventFromResponse(info map[string]any) (*CalendarEvent, error) {
+	event, ok := info["event"].(map[string]any)
+	if !ok {
+		return nil, errors.New("event is missing in response")
+	}
+	return NewCalendarEvent(event), nil
 }
 
 func (c AppClient) CalendarEventList(calendarId string) []CalendarEvent {
+	calendarEvents, err := c.CalendarEventListWithError(calendarId)
+	if err != nil {
+		logrus.WithField("CalendarID", calendarId).WithError(err).Warn("nil calendar event return")
+		return nil
+	}
+	return calendarEvents
+}
+
+func (c AppClient) CalendarEventListWithError(calendarId string) ([]CalendarEvent, error) {
+	return c.CalendarEventListWithContext(context.Background(), calendarId)
+}
+
+func (c AppClient) CalendarEventListWithContext(ctx context.Context, calendarId string) ([]CalendarEvent, error) {
 	query := make(map[string]any)
 	query["anchor_time"] = strconv.FormatInt(time.Now().Unix(), 10)
 
-	events := c.GetAllPages("get", "open-apis/calendar/v4/calendars/"+calendarId+"/events", query, nil, nil, 100)
+	events, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/calendar/v4/calendars/"+calendarId+"/events", query, nil, nil, 100)
+	if err != nil {
+		return nil, err
+	}
 	var calendarEvents []CalendarEvent
 	for _, event := range events {
 		calendarEvents = append(calendarEvents, *NewCalendarEvent(event.(map[string]any)))
 	}
-	return calendarEvents
+	return calendarEvents, nil
 }
 
 type CalendarEventAttendeeType string
@@ -211,32 +277,72 @@ func (c *CalendarEventAttendeeCreateRequest) WithNeedNotification(needNotificati
 }
 
 func (c AppClient) CalendarEventAttendeeCreate(calendarId string, eventId string, userIdType UserIdType, attendee *CalendarEventAttendeeCreateRequest) []CalendarEventAttendee {
+	attendees, err := c.CalendarEventAttendeeCreateWithError(calendarId, eventId, userIdType, attendee)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"CalendarID": calendarId,
+			"EventID":    eventId,
+			"error":      err,
+		}).Error("create calendar event attendee fail")
+		return []CalendarEventAttendee{}
+	}
+	return attendees
+}
+
+func (c AppClient) CalendarEventAttendeeCreateWithError(calendarId string, eventId string, userIdType UserIdType, attendee *CalendarEventAttendeeCreateRequest) ([]CalendarEventAttendee, error) {
+	return c.CalendarEventAttendeeCreateWithContext(context.Background(), calendarId, eventId, userIdType, attendee)
+}
+
+func (c AppClient) CalendarEventAttendeeCreateWithContext(ctx context.Context, calendarId string, eventId string, userIdType UserIdType, attendee *CalendarEventAttendeeCreateRequest) ([]CalendarEventAttendee, error) {
 	query := make(map[string]any)
 	query["user_id_type"] = string(userIdType)
 
 	body := make(map[string]any)
-	struct2map(attendee, &body)
+	if err := struct2map(attendee, &body); err != nil {
+		return nil, err
+	}
 
-	info := c.Request("post", "open-apis/calendar/v4/calendars/"+calendarId+"/events/"+eventId+"/attendees", query, nil, body)
-	attendees_ := info["attendees"].([]any)
+	info, err := c.RequestWithContext(ctx, "post", "open-apis/calendar/v4/calendars/"+calendarId+"/events/"+eventId+"/attendees", query, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	attendees_, _ := info["attendees"].([]any)
 
-	attendees := []CalendarEventAttendee{}
-	for _, attendee_ := range attendees_ {
-		attendee := CalendarEventAttendee{}
-		map2struct(attendee_.(map[string]any), &attendee)
-		attendees = append(attendees, attendee)
+	return newCalendarEventAttendees(attendees_), nil
+}
+
+func (c AppClient) CalendarEventAttendeeQuery(calendarId string, eventId string, userIdType UserIdType) []CalendarEventAttendee {
+	attendees, err := c.CalendarEventAttendeeQueryWithError(calendarId, eventId, userIdType)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"CalendarID": calendarId,
+			"EventID":    eventId,
+			"error":      err,
+		}).Warn("nil calendar event attendee return")
+		return []CalendarEventAttendee{}
 	}
 	return attendees
 }
 
-func (c AppClient) CalendarEventAttendeeQuery(calendarId string, eventId string, userIdType UserIdType) []CalendarEventAttendee {
+func (c AppClient) CalendarEventAttendeeQueryWithError(calendarId string, eventId string, userIdType UserIdType) ([]CalendarEventAttendee, error) {
+	return c.CalendarEventAttendeeQueryWithContext(context.Background(), calendarId, eventId, userIdType)
+}
+
+func (c AppClient) CalendarEventAttendeeQueryWithContext(ctx context.Context, calendarId string, eventId string, userIdType UserIdType) ([]CalendarEventAttendee, error) {
 	query := make(map[string]any)
 	query["user_id_type"] = string(userIdType)
 
-	info := c.GetAllPages("get", "open-apis/calendar/v4/calendars/"+calendarId+"/events/"+eventId+"/attendees", query, nil, nil, 100)
+	info, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/calendar/v4/calendars/"+calendarId+"/events/"+eventId+"/attendees", query, nil, nil, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCalendarEventAttendees(info), nil
+}
 
+func newCalendarEventAttendees(data []any) []CalendarEventAttendee {
 	attendees := []CalendarEventAttendee{}
-	for _, attendee_ := range info {
+	for _, attendee_ := range data {
 		attendee := CalendarEventAttendee{}
 		map2struct(attendee_.(map[string]any), &attendee)
 		attendees = append(attendees, attendee)
@@ -0,0 +1,330 @@
+package feishuapi
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CardAction is the interaction of a user with a message card,
+// such as clicking a button or choosing an option of an overflow, a select menu or a date picker
+type CardAction struct {
+	OpenId        string
+	UserId        string
+	UnionId       string
+	TenantKey     string
+	OpenMessageId string
+	OpenChatId    string
+	// the token to update the card with later, "c-" prefixed in the v1.0 callbacks.
+	// It is not the verification token
+	Token string
+	// the tag of the element which is interacted with, e.g. "button", "overflow", "select_static", "date_picker"
+	Tag string
+	// the value given by WithValue of the element
+	Value map[string]any
+	// the chosen option of an overflow or a select menu, or the chosen date or time of a picker
+	Option    string
+	Timezone  string
+	FormValue map[string]any
+}
+
+type CardToastType string
+
+const (
+	CardToastInfo    CardToastType = "info"
+	CardToastSuccess CardToastType = "success"
+	CardToastError   CardToastType = "error"
+	CardToastWarning CardToastType = "warning"
+)
+
+type CardToast struct {
+	Type    CardToastType `json:"type"`
+	Content string        `json:"content"`
+}
+
+// CardActionResponse updates the card in place with Card, and/or shows Toast to the user
+type CardActionResponse struct {
+	Card  *MessageCard
+	Toast *CardToast
+}
+
+// CardActionHandler handles a card action, return nil to keep the card unchanged
+type CardActionHandler func(ctx context.Context, action *CardAction) (*CardActionResponse, error)
+
+// CardActionHandler receives the card actions sent to the card request url of the app.
+// It answers the url_verification challenge, decrypts the request with Conf.EncryptKey,
+// checks the signature of every action and the verification token of the url_verification and v2.0 requests,
+// then hands the action to handle
+func (c AppClient) CardActionHandler(handle CardActionHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxEventBodySize))
+		if err != nil {
+			http.Error(w, "read body fail", http.StatusBadRequest)
+			return
+		}
+
+		payload := body
+		var encrypted struct {
+			Encrypt string `json:"encrypt"`
+		}
+		if json.Unmarshal(body, &encrypted) == nil && encrypted.Encrypt != "" {
+			if c.Conf.EncryptKey == "" {
+				http.Error(w, "card action is encrypted but EncryptKey is not configured", http.StatusBadRequest)
+				return
+			}
+			if payload, err = decryptEvent(c.Conf.EncryptKey, encrypted.Encrypt); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		action, challenge, schema, token, err := parseCardAction(payload)
+		if err != nil {
+			logrus.WithError(err).Warn("parse feishu card action fail")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// the url_verification request isn't signed
+		if challenge == "" {
+			if err := c.checkCardSignature(r.Header, body); err != nil {
+				logrus.WithError(err).Warn("reject feishu card action")
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		if challenge != "" || schema != "1.0" {
+			if err := c.checkEventToken(token); err != nil {
+				logrus.WithError(err).Warn("reject feishu card action")
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		if challenge != "" {
+			writeEventJSON(w, map[string]string{"challenge": challenge})
+			return
+		}
+
+		resp, err := handle(r.Context(), action)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"MessageID": action.OpenMessageId,
+				"OpenID":    action.OpenId,
+			}).WithError(err).Error("handle feishu card action fail")
+			http.Error(w, "handle card action fail", http.StatusInternalServerError)
+			return
+		}
+		writeEventJSON(w, cardActionResponseBody(resp, schema))
+	})
+}
+
+// The card callbacks are signed with sha1(timestamp + nonce + verification_token + body),
+// the ones pushed as events with sha256(timestamp + nonce + encrypt_key + body).
+// The signature is required unless neither Conf.VerificationToken nor Conf.EncryptKey is configured
+func (c AppClient) checkCardSignature(header http.Header, body []byte) error {
+	if c.Conf.VerificationToken == "" && c.Conf.EncryptKey == "" {
+		return nil
+	}
+	if header.Get("X-Lark-Signature") == "" {
+		return ErrInvalidEventSignature
+	}
+
+	// without a verification token anyone can compute the sha1 signature, so only the sha256 one is accepted
+	if c.Conf.VerificationToken != "" {
+		h := sha1.New()
+		h.Write([]byte(header.Get("X-Lark-Request-Timestamp")))
+		h.Write([]byte(header.Get("X-Lark-Request-Nonce")))
+		h.Write([]byte(c.Conf.VerificationToken))
+		h.Write(body)
+		if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(h.Sum(nil))), []byte(header.Get("X-Lark-Signature"))) == 1 {
+			return nil
+		}
+	}
+	if c.Conf.EncryptKey != "" {
+		return c.checkEventSignature(header, body)
+	}
+	return ErrInvalidEventSignature
+}
+
+type cardActionValue struct {
+	Value     map[string]any `json:"value"`
+	Tag       string         `json:"tag"`
+	Option    string         `json:"option"`
+	Timezone  string         `json:"timezone"`
+	FormValue map[string]any `json:"form_value"`
+}
+
+// Parse the v1.0 callback or the v2.0 card.action.trigger event,
+// return the action, or the challenge of a url_verification request, with the schema and the verification token.
+// The v1.0 callbacks carry no verification token, their "token" is the token to update the card
+func parseCardAction(payload []byte) (*CardAction, string, string, string, error) {
+	var data struct {
+		// url_verification
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+
+		// v2.0
+		Schema string `json:"schema"`
+		Header struct {
+			Token     string `json:"token"`
+			TenantKey string `json:"tenant_key"`
+		} `json:"header"`
+		Event struct {
+			Operator struct {
+				TenantKey string `json:"tenant_key"`
+				OpenId    string `json:"open_id"`
+				UserId    string `json:"user_id"`
+				UnionId   string `json:"union_id"`
+			} `json:"operator"`
+			Token   string          `json:"token"`
+			Action  cardActionValue `json:"action"`
+			Context struct {
+				OpenMessageId string `json:"open_message_id"`
+				OpenChatId    string `json:"open_chat_id"`
+			} `json:"context"`
+		} `json:"event"`
+
+		// v1.0
+		OpenId        string          `json:"open_id"`
+		UserId        string          `json:"user_id"`
+		UnionId       string          `json:"union_id"`
+		TenantKey     string          `json:"tenant_key"`
+		OpenMessageId string          `json:"open_message_id"`
+		OpenChatId    string          `json:"open_chat_id"`
+		Token         string          `json:"token"`
+		Action        cardActionValue `json:"action"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, "", "", "", fmt.Errorf("decode card action: %w", err)
+	}
+
+	if data.Type == "url_verification" {
+		return nil, data.Challenge, "", data.Token, nil
+	}
+
+	if data.Schema != "" {
+		event := data.Event
+		tenantKey := event.Operator.TenantKey
+		if tenantKey == "" {
+			tenantKey = data.Header.TenantKey
+		}
+		return &CardAction{
+			OpenId:        event.Operator.OpenId,
+			UserId:        event.Operator.UserId,
+			UnionId:       event.Operator.UnionId,
+			TenantKey:     tenantKey,
+			OpenMessageId: event.Context.OpenMessageId,
+			OpenChatId:    event.Context.OpenChatId,
+			Token:         event.Token,
+			Tag:           event.Action.Tag,
+			Value:         event.Action.Value,
+			Option:        event.Action.Option,
+			Timezone:      event.Action.Timezone,
+			FormValue:     event.Action.FormValue,
+		}, "", data.Schema, data.Header.Token, nil
+	}
+
+	if data.OpenMessageId == "" && data.OpenId == "" {
+		return nil, "", "", "", errors.New("not a card action")
+	}
+	return &CardAction{
+		OpenId:        data.OpenId,
+		UserId:        data.UserId,
+		UnionId:       data.UnionId,
+		TenantKey:     data.TenantKey,
+		OpenMessageId: data.OpenMessageId,
+		OpenChatId:    data.OpenChatId,
+		Token:         data.Token,
+		Tag:           data.Action.Tag,
+		Value:         data.Action.Value,
+		Option:        data.Action.Option,
+		Timezone:      data.Action.Timezone,
+		FormValue:     data.Action.FormValue,
+	}, "", "1.0", "", nil
+}
+
+// The v1.0 callback is answered with the card itself, the v2.0 one with {"toast": ..., "card": {"type": "raw", "data": ...}}
+func cardActionResponseBody(resp *CardActionResponse, schema string) any {
+	if resp == nil {
+		return map[string]any{}
+	}
+	if schema == "1.0" && resp.Toast == nil && resp.Card != nil {
+		return resp.Card
+	}
+
+	body := make(map[string]any)
+	if resp.Toast != nil {
+		body["toast"] = resp.Toast
+	}
+	if resp.Card != nil {
+		if schema == "1.0" {
+			body["card"] = resp.Card
+		} else {
+			body["card"] = map[string]any{"type": "raw", "data": resp.Card}
+		}
+	}
+	return body
+}
+
+// CardActionRouter routes the card actions by the value of a key in CardAction.Value,
+// e.g. a button built with WithValue(map[string]interface{}{"action": "approve"}) goes to the handler of "approve"
+type CardActionRouter struct {
+	key string
+
+	mu       sync.RWMutex
+	handlers map[string]CardActionHandler
+	fallback CardActionHandler
+}
+
+// Route by the value of key, "action" if it is empty
+func NewCardActionRouter(key string) *CardActionRouter {
+	if key == "" {
+		key = "action"
+	}
+	return &CardActionRouter{
+		key:      key,
+		handlers: make(map[string]CardActionHandler),
+	}
+}
+
+func (r *CardActionRouter) On(value string, handler CardActionHandler) *CardActionRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[value] = handler
+	return r
+}
+
+// Handle the actions which have no handler, they are ignored if it is nil
+func (r *CardActionRouter) OnUnknown(handler CardActionHandler) *CardActionRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = handler
+	return r
+}
+
+// Handle is a CardActionHandler which calls the handler of the action
+func (r *CardActionRouter) Handle(ctx context.Context, action *CardAction) (*CardActionResponse, error) {
+	value, _ := action.Value[r.key].(string)
+
+	r.mu.RLock()
+	handler, ok := r.handlers[value]
+	if !ok {
+		handler = r.fallback
+	}
+	r.mu.RUnlock()
+
+	if handler == nil {
+		logrus.WithField(r.key, value).Debug("no handler for feishu card action")
+		return nil, nil
+	}
+	return handler(ctx, action)
+}
@@ -0,0 +1,343 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CommandScope int
+
+const (
+	// the command can be used in both group and p2p chats
+	CommandScopeAll CommandScope = iota
+	CommandScopeGroup
+	CommandScopeP2P
+)
+
+type CommandArg struct {
+	Name        string
+	Description string
+	Required    bool
+	// take all the remaining text, including the spaces, it must be the last arg
+	Rest bool
+}
+
+type Command struct {
+	// the name without prefix, e.g. "deploy" for "/deploy"
+	Name        string
+	Description string
+	Args        []CommandArg
+	Scope       CommandScope
+	Handler     CommandHandler
+}
+
+// CommandContext is the parsed command which is passed to CommandHandler
+type CommandContext struct {
+	Client  AppClient
+	Event   *MessageReceiveEvent
+	Command *Command
+	Args    map[string]string
+	// the text after the command name
+	Raw string
+}
+
+type CommandHandler func(ctx context.Context, cmd *CommandContext) error
+
+func (cmd *CommandContext) Arg(name string) string {
+	return cmd.Args[name]
+}
+
+// Reply a text message to the chat where the command is sent
+func (cmd *CommandContext) ReplyText(ctx context.Context, text string) error {
+	_, err := cmd.Client.MessageSendWithContext(ctx, GroupChatId, cmd.Event.Message.ChatId, Text, text)
+	return err
+}
+
+// Reply a message card to the chat where the command is sent
+func (cmd *CommandContext) ReplyCard(ctx context.Context, card *MessageCard) error {
+	return replyCard(ctx, cmd.Client, cmd.Event.Message.ChatId, card)
+}
+
+func replyCard(ctx context.Context, c AppClient, chatId string, card *MessageCard) error {
+	content, err := card.String()
+	if err != nil {
+		return err
+	}
+	_, err = c.MessageSendWithContext(ctx, GroupChatId, chatId, Interactive, content)
+	return err
+}
+
+// CommandRouter parses the "/deploy foo" style commands out of the text messages sent to the bot.
+// Use HandleMessage as the handler of EventDispatcher.OnMessageReceive
+type CommandRouter struct {
+	client AppClient
+	prefix string
+
+	mu       sync.RWMutex
+	commands map[string]*Command
+	botId    string
+}
+
+// Create a CommandRouter which replies through c, "/help" is registered by default
+func (c AppClient) NewCommandRouter() *CommandRouter {
+	r := &CommandRouter{
+		client:   c,
+		prefix:   "/",
+		commands: make(map[string]*Command),
+	}
+	r.Register(Command{
+		Name:        "help",
+		Description: "Show the available commands",
+		Handler: func(ctx context.Context, cmd *CommandContext) error {
+			return cmd.ReplyCard(ctx, r.HelpCard(cmd.Event.Message.ChatType))
+		},
+	})
+	return r
+}
+
+// The prefix of the commands, "/" by default
+func (r *CommandRouter) WithPrefix(prefix string) *CommandRouter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefix = prefix
+	return r
+}
+
+// Register the command, it replaces the command with the same name.
+// It panics if the handler is nil, like http.Handle
+func (r *CommandRouter) Register(cmd Command) *CommandRouter {
+	if cmd.Handler == nil {
+		panic("feishuapi: nil handler of command " + strconv.Quote(cmd.Name))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[cmd.Name] = &cmd
+	return r
+}
+
+func (r *CommandRouter) HandleMessage(ctx context.Context, event *MessageReceiveEvent) error {
+	if event.Message.MessageType != string(Text) || event.SenderType == "app" {
+		return nil
+	}
+	var content struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(event.Message.Content), &content); err != nil {
+		return fmt.Errorf("decode text message: %w", err)
+	}
+
+	text, mentioned := r.stripBotMentions(ctx, content.Text, event.Message.Mentions)
+
+	r.mu.RLock()
+	prefix := r.prefix
+	r.mu.RUnlock()
+	if !strings.HasPrefix(text, prefix) {
+		return nil
+	}
+	text = strings.TrimPrefix(text, prefix)
+	name, raw := text, ""
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		name, raw = text[:i], strings.TrimSpace(text[i:])
+	}
+
+	r.mu.RLock()
+	cmd, ok := r.commands[name]
+	r.mu.RUnlock()
+
+	reply := &CommandContext{Client: r.client, Event: event, Command: cmd, Raw: raw}
+	if !ok {
+		// a "/x" in a group may be meant for another bot or just be a path, only answer when the bot is mentioned
+		if event.Message.ChatType == "group" && !mentioned {
+			return nil
+		}
+		return reply.ReplyCard(ctx, r.HelpCard(event.Message.ChatType).
+			WithHeader(helpCardHeader("Unknown command "+prefix+name, TemplateOrange)))
+	}
+	if !cmd.Scope.allows(event.Message.ChatType) {
+		return reply.ReplyText(ctx, prefix+name+" is only available in "+cmd.Scope.String()+" chats")
+	}
+
+	args, err := parseCommandArgs(cmd.Args, raw)
+	if err != nil {
+		return reply.ReplyText(ctx, err.Error()+"\nUsage: "+prefix+cmd.usage())
+	}
+	reply.Args = args
+	return cmd.Handler(ctx, reply)
+}
+
+// Build the help card of the commands which can be used in chatType ("group" or "p2p", "" for all)
+func (r *CommandRouter) HelpCard(chatType string) *MessageCard {
+	r.mu.RLock()
+	prefix := r.prefix
+	var commands []*Command
+	for _, cmd := range r.commands {
+		if chatType == "" || cmd.Scope.allows(chatType) {
+			commands = append(commands, cmd)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+
+	var lines []string
+	for _, cmd := range commands {
+		line := "**" + prefix + cmd.usage() + "**"
+		if cmd.Description != "" {
+			line += "  " + cmd.Description
+		}
+		for _, arg := range cmd.Args {
+			if arg.Description != "" {
+				line += "\n    " + arg.Name + ": " + arg.Description
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return NewMessageCard().
+		WithHeader(helpCardHeader("Commands", TemplateBlue)).
+		WithElements([]MessageCardElement{
+			NewMessageCardDiv().
+				WithText(NewMessageCardLarkMarkdown().WithContent(strings.Join(lines, "\n")).Build()).
+				Build(),
+		}).
+		Build()
+}
+
+func helpCardHeader(title string, template MessageCardTitleTemplate) *MessageCardHeader {
+	return NewMessageCardHeader().
+		WithTitle(NewMessageCardPlainText().WithContent(title).Build()).
+		WithTemplate(template).
+		Build()
+}
+
+// Remove the mentions of the bot, so that "@bot /deploy foo" is parsed as "/deploy foo".
+// Also report whether the bot is mentioned in the text
+func (r *CommandRouter) stripBotMentions(ctx context.Context, text string, mentions []MessageMention) (string, bool) {
+	if len(mentions) == 0 {
+		return strings.TrimSpace(text), false
+	}
+
+	botId := r.botOpenId(ctx)
+	mentioned := false
+	for _, mention := range mentions {
+		if botId != "" && mention.Id.OpenId == botId {
+			stripped := removeMentionKey(text, mention.Key)
+			mentioned = mentioned || stripped != text
+			text = stripped
+		}
+	}
+	return strings.TrimSpace(text), mentioned
+}
+
+// Remove the key like "@_user_1", but not the prefix of "@_user_10"
+func removeMentionKey(text string, key string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, key)
+		if i < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		end := i + len(key)
+		if end < len(text) && text[end] >= '0' && text[end] <= '9' {
+			b.WriteString(text[:end])
+		} else {
+			b.WriteString(text[:i])
+		}
+		text = text[end:]
+	}
+}
+
+// Get the open_id of the bot once, it is retried by the next message if it fails
+func (r *CommandRouter) botOpenId(ctx context.Context) string {
+	r.mu.RLock()
+	botId := r.botId
+	r.mu.RUnlock()
+	if botId != "" {
+		return botId
+	}
+
+	info, err := r.client.RobotGetInfoWithContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Warn("get bot info fail, the bot mentions are kept")
+		return ""
+	}
+	r.mu.Lock()
+	r.botId = info.OpenId
+	r.mu.Unlock()
+	return info.OpenId
+}
+
+func (s CommandScope) allows(chatType string) bool {
+	switch s {
+	case CommandScopeGroup:
+		return chatType == "group"
+	case CommandScopeP2P:
+		return chatType == "p2p"
+	}
+	return true
+}
+
+func (s CommandScope) String() string {
+	switch s {
+	case CommandScopeGroup:
+		return "group"
+	case CommandScopeP2P:
+		return "p2p"
+	}
+	return "all"
+}
+
+func (cmd *Command) usage() string {
+	usage := cmd.Name
+	for _, arg := range cmd.Args {
+		name := arg.Name
+		if arg.Rest {
+			name += "..."
+		}
+		if arg.Required {
+			usage += " <" + name + ">"
+		} else {
+			usage += " [" + name + "]"
+		}
+	}
+	return usage
+}
+
+func parseCommandArgs(specs []CommandArg, raw string) (map[string]string, error) {
+	args := make(map[string]string)
+	rest := raw
+	for _, spec := range specs {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			if spec.Required {
+				return nil, fmt.Errorf("missing argument %s", spec.Name)
+			}
+			continue
+		}
+		if spec.Rest {
+			args[spec.Name] = strings.TrimSpace(rest)
+			rest = ""
+			break
+		}
+
+		value := rest
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			value, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		args[spec.Name] = value
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("too many arguments")
+	}
+	return args, nil
+}
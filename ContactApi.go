@@ -1,6 +1,11 @@
 package feishuapi
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
 
 type UserInfo struct {
 	UnionId       string
@@ -11,39 +16,71 @@ type UserInfo struct {
 }
 
 func (c AppClient) UserInfoById(UserId string, IdType UserIdType) *UserInfo {
+	user, err := c.UserInfoByIdWithError(UserId, IdType)
+	if err != nil {
+		logrus.WithField("UserId", UserId).WithError(err).Warn("nil user info return")
+		return nil
+	}
+	return user
+}
+
+func (c AppClient) UserInfoByIdWithError(UserId string, IdType UserIdType) (*UserInfo, error) {
+	return c.UserInfoByIdWithContext(context.Background(), UserId, IdType)
+}
+
+func (c AppClient) UserInfoByIdWithContext(ctx context.Context, UserId string, IdType UserIdType) (*UserInfo, error) {
 	query := make(map[string]any)
 	query["user_id_type"] = string(IdType)
-	data := c.Request("get", "open-apis/contact/v3/users/"+UserId, query, nil, nil)
-	if data == nil {
-		logrus.WithField("UserId", UserId).Warn("nil user info return")
-		return nil
+	data, err := c.RequestWithContext(ctx, "get", "open-apis/contact/v3/users/"+UserId, query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := data["user"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("user is missing in response")
 	}
-	user := data["user"].(map[string]interface{})
 	return &UserInfo{
 		UnionId:       user["union_id"].(string),
 		OpenId:        user["open_id"].(string),
 		UserId:        user["user_id"].(string),
 		Name:          user["name"].(string),
 		DepartmentIds: user["department_ids"].([]interface{}),
-	}
+	}, nil
 }
 
 func (c AppClient) UserInfoByName(name string, userAccessToken string) *UserInfo {
-	headers := make(map[string]string)
-	headers["Authorization"] = userAccessToken
+	user, err := c.UserInfoByNameWithError(name, userAccessToken)
+	if err != nil {
+		logrus.WithField("name", name).WithError(err).Warn("nil user info return")
+		return nil
+	}
+	return user
+}
+
+func (c AppClient) UserInfoByNameWithError(name string, userAccessToken string) (*UserInfo, error) {
+	return c.UserInfoByNameWithContext(context.Background(), name, StaticUserToken(userAccessToken))
+}
+
+func (c AppClient) UserInfoByNameWithContext(ctx context.Context, name string, userToken UserToken) (*UserInfo, error) {
+	headers, err := userAuthHeaders(ctx, userToken)
+	if err != nil {
+		return nil, err
+	}
 
 	query := make(map[string]any)
 	query["query"] = name
-	data := c.Request("get", "open-apis/search/v1/user", query, headers, nil)
-	if data == nil {
-		logrus.WithField("name", name).Warn("nil user info return")
-		return nil
+	data, err := c.RequestWithContext(ctx, "get", "open-apis/search/v1/user", query, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := data["user"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("user is missing in response")
 	}
-	user := data["user"].(map[string]interface{})
 	return &UserInfo{
 		OpenId:        user["open_id"].(string),
 		UserId:        user["user_id"].(string),
 		Name:          user["name"].(string),
 		DepartmentIds: user["department_ids"].([]interface{}),
-	}
+	}, nil
 }
@@ -1,6 +1,10 @@
 package feishuapi
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
 
 type DepartmentInfo struct {
 	Name        string
@@ -20,10 +24,22 @@ func NewDepartmentInfo(data map[string]any) *DepartmentInfo {
 
 // Send a request to get the information of a department by department_id
 func (c AppClient) DepartmentGetInfoById(DepartmentId string) *DepartmentInfo {
-	data := c.Request("get", "open-apis/contact/v3/departments/"+DepartmentId, nil, nil, nil)
-	if data == nil {
-		logrus.WithField("DepartmentID", DepartmentId).Warn("nil department info return")
+	info, err := c.DepartmentGetInfoByIdWithError(DepartmentId)
+	if err != nil {
+		logrus.WithField("DepartmentID", DepartmentId).WithError(err).Warn("nil department info return")
 		return nil
 	}
-	return NewDepartmentInfo(data)
+	return info
+}
+
+func (c AppClient) DepartmentGetInfoByIdWithError(DepartmentId string) (*DepartmentInfo, error) {
+	return c.DepartmentGetInfoByIdWithContext(context.Background(), DepartmentId)
+}
+
+func (c AppClient) DepartmentGetInfoByIdWithContext(ctx context.Context, DepartmentId string) (*DepartmentInfo, error) {
+	data, err := c.RequestWithContext(ctx, "get", "open-apis/contact/v3/departments/"+DepartmentId, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewDepartmentInfo(data), nil
 }
@@ -1,9 +1,8 @@
 package feishuapi
 
 import (
-	"encoding/json"
-	"io"
-	"net/http"
+	"context"
+	"errors"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -27,13 +26,24 @@ func NewBitableInfo(data map[string]any) *BitableInfo {
 
 // Get all Bitables in a Document
 func (c AppClient) DocumentGetAllBitables(DocumentId string) []BitableInfo {
-	var all_bitables []BitableInfo
+	all_bitables, err := c.DocumentGetAllBitablesWithError(DocumentId)
+	if err != nil {
+		logrus.WithField("DocumentID", DocumentId).WithError(err).Warn("nil bitable info return")
+		return nil
+	}
+	return all_bitables
+}
 
-	l := c.GetAllPages("get", "open-apis/docx/v1/documents/"+DocumentId+"/blocks", nil, nil, nil, 100)
+func (c AppClient) DocumentGetAllBitablesWithError(DocumentId string) ([]BitableInfo, error) {
+	return c.DocumentGetAllBitablesWithContext(context.Background(), DocumentId)
+}
 
-	if l == nil {
-		logrus.WithField("DocumentID", DocumentId).Warn("nil bitable info return")
-		return nil
+func (c AppClient) DocumentGetAllBitablesWithContext(ctx context.Context, DocumentId string) ([]BitableInfo, error) {
+	var all_bitables []BitableInfo
+
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/docx/v1/documents/"+DocumentId+"/blocks", nil, nil, nil, 100)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, value := range l {
@@ -43,7 +53,7 @@ func (c AppClient) DocumentGetAllBitables(DocumentId string) []BitableInfo {
 		}
 	}
 
-	return all_bitables
+	return all_bitables, nil
 }
 
 type TableInfo struct {
@@ -65,20 +75,31 @@ func NewTableInfo(AppToken string, data map[string]any) *TableInfo {
 
 // Get all tables by AppToken
 func (c AppClient) DocumentGetAllTables(AppToken string) []TableInfo {
-	var all_tables []TableInfo
+	all_tables, err := c.DocumentGetAllTablesWithError(AppToken)
+	if err != nil {
+		logrus.WithField("AppToken", AppToken).WithError(err).Warn("nil table info return")
+		return nil
+	}
+	return all_tables
+}
+
+func (c AppClient) DocumentGetAllTablesWithError(AppToken string) ([]TableInfo, error) {
+	return c.DocumentGetAllTablesWithContext(context.Background(), AppToken)
+}
 
-	l := c.GetAllPages("get", "open-apis/bitable/v1/apps/"+AppToken+"/tables", nil, nil, nil, 100)
+func (c AppClient) DocumentGetAllTablesWithContext(ctx context.Context, AppToken string) ([]TableInfo, error) {
+	var all_tables []TableInfo
 
-	if l == nil {
-		logrus.WithField("AppToken", AppToken).Warn("nil table info return")
-		return nil
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/bitable/v1/apps/"+AppToken+"/tables", nil, nil, nil, 100)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, value := range l {
 		all_tables = append(all_tables, *NewTableInfo(AppToken, value.(map[string]any)))
 	}
 
-	return all_tables
+	return all_tables, nil
 }
 
 type RecordInfo struct {
@@ -111,25 +132,27 @@ func NewRecordInfoWithoutModifiedTime(AppToken string, TableId string, data map[
 
 // Get all Records by AppToken and TableId
 func (c AppClient) DocumentGetAllRecords(AppToken string, TableId string) []RecordInfo {
-	var all_records []RecordInfo
-
-	query := make(map[string]any)
-	query["automatic_fields"] = "true"
-	l := c.GetAllPages("get", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records", query, nil, nil, 100)
-
-	if l == nil {
+	all_records, err := c.DocumentGetAllRecordsWithError(AppToken, TableId)
+	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"AppToken": AppToken,
 			"TableID":  TableId,
+			"error":    err,
 		}).Warn("nil record info return")
 		return nil
 	}
+	return all_records
+}
 
-	for _, value := range l {
-		all_records = append(all_records, *NewRecordInfo(AppToken, TableId, value.(map[string]any)))
-	}
+func (c AppClient) DocumentGetAllRecordsWithError(AppToken string, TableId string) ([]RecordInfo, error) {
+	return c.DocumentGetAllRecordsWithContext(context.Background(), AppToken, TableId)
+}
 
-	return all_records
+func (c AppClient) DocumentGetAllRecordsWithContext(ctx context.Context, AppToken string, TableId string) ([]RecordInfo, error) {
+	query := make(map[string]any)
+	query["automatic_fields"] = "true"
+
+	return c.documentGetAllRecords(ctx, AppToken, TableId, query)
 }
 
 // DocumentGetAllRecordsWithLinks retrieves all records from a specified table in the Bitable app.
@@ -138,92 +161,132 @@ func (c AppClient) DocumentGetAllRecords(AppToken string, TableId string) []Reco
 // Note: Adding the query parameter "text_field_as_array" will change the format of multi-line text fields
 // from string to []map.
 func (c AppClient) DocumentGetAllRecordsWithLinks(AppToken string, TableId string) []RecordInfo {
-	var all_records []RecordInfo
+	all_records, err := c.DocumentGetAllRecordsWithLinksWithError(AppToken, TableId)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"AppToken": AppToken,
+			"TableID":  TableId,
+			"error":    err,
+		}).Warn("nil record info return")
+		return nil
+	}
+	return all_records
+}
+
+func (c AppClient) DocumentGetAllRecordsWithLinksWithError(AppToken string, TableId string) ([]RecordInfo, error) {
+	return c.DocumentGetAllRecordsWithLinksWithContext(context.Background(), AppToken, TableId)
+}
 
+func (c AppClient) DocumentGetAllRecordsWithLinksWithContext(ctx context.Context, AppToken string, TableId string) ([]RecordInfo, error) {
 	query := make(map[string]any)
 	query["automatic_fields"] = "true"
 	query["text_field_as_array"] = "true" // Include this query parameter to get multi-line text fields as []map with hyperlinks.
 
-	l := c.GetAllPages("get", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records", query, nil, nil, 100)
+	return c.documentGetAllRecords(ctx, AppToken, TableId, query)
+}
 
-	if l == nil {
-		logrus.WithFields(logrus.Fields{
-			"AppToken": AppToken,
-			"TableID":  TableId,
-		}).Warn("nil record info return")
-		return nil
+func (c AppClient) documentGetAllRecords(ctx context.Context, AppToken string, TableId string, query map[string]any) ([]RecordInfo, error) {
+	var all_records []RecordInfo
+
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records", query, nil, nil, 100)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, value := range l {
 		all_records = append(all_records, *NewRecordInfo(AppToken, TableId, value.(map[string]any)))
 	}
 
-	return all_records
+	return all_records, nil
 }
 
 // Get A Record by AppToken, TableId and RecordId
 func (c AppClient) DocumentGetRecord(AppToken string, TableId string, RecordId string) *RecordInfo {
-	query := make(map[string]any)
-	query["automatic_fields"] = "true"
-	record := c.Request("get", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/"+RecordId, query, nil, nil)
-
-	if record == nil {
-		logrus.WithFields(logrus.Fields{
-			"AppToken": AppToken,
-			"TableID":  TableId,
-			"RecordID": RecordId,
-		}).Warn("nil record info return")
+	record, err := c.DocumentGetRecordWithError(AppToken, TableId, RecordId)
+	if err != nil {
+		logDocumentRecordError(AppToken, TableId, RecordId, err)
 		return nil
 	}
+	return record
+}
 
-	return NewRecordInfo(AppToken, TableId, record["record"].(map[string]any))
+func (c AppClient) DocumentGetRecordWithError(AppToken string, TableId string, RecordId string) (*RecordInfo, error) {
+	return c.DocumentGetRecordWithContext(context.Background(), AppToken, TableId, RecordId)
 }
 
-func (c AppClient) DocumentGetRecordWithoutModifiedTime(AppToken string, TableId string, RecordId string) *RecordInfo {
-	query := make(map[string]any)
-	query["automatic_fields"] = "true"
-	record := c.Request("get", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/"+RecordId, query, nil, nil)
+func (c AppClient) DocumentGetRecordWithContext(ctx context.Context, AppToken string, TableId string, RecordId string) (*RecordInfo, error) {
+	record, err := c.documentGetRecord(ctx, AppToken, TableId, RecordId)
+	if err != nil {
+		return nil, err
+	}
+	return NewRecordInfo(AppToken, TableId, record), nil
+}
 
-	if record == nil {
-		logrus.WithFields(logrus.Fields{
-			"AppToken": AppToken,
-			"TableID":  TableId,
-			"RecordID": RecordId,
-		}).Warn("nil record info return")
+func (c AppClient) DocumentGetRecordWithoutModifiedTime(AppToken string, TableId string, RecordId string) *RecordInfo {
+	record, err := c.DocumentGetRecordWithoutModifiedTimeWithError(AppToken, TableId, RecordId)
+	if err != nil {
+		logDocumentRecordError(AppToken, TableId, RecordId, err)
 		return nil
 	}
-
-	return NewRecordInfoWithoutModifiedTime(AppToken, TableId, record["record"].(map[string]any))
+	return record
 }
 
-// Get a []Byte form Record by AppToken, TableId and RecordId
-func (c AppClient) DocumentGetRecordInByte(AppToken string, TableId string, RecordId string) []byte {
-	client := &http.Client{}
-	url := "https://open.feishu.cn/open-apis/bitable/v1/apps/" + AppToken + "/tables/" + TableId + "/records/" + RecordId
-	req, err := http.NewRequest("GET", url, nil)
+func (c AppClient) DocumentGetRecordWithoutModifiedTimeWithError(AppToken string, TableId string, RecordId string) (*RecordInfo, error) {
+	return c.DocumentGetRecordWithoutModifiedTimeWithContext(context.Background(), AppToken, TableId, RecordId)
+}
 
+func (c AppClient) DocumentGetRecordWithoutModifiedTimeWithContext(ctx context.Context, AppToken string, TableId string, RecordId string) (*RecordInfo, error) {
+	record, err := c.documentGetRecord(ctx, AppToken, TableId, RecordId)
 	if err != nil {
-		logrus.Error(err)
-		return nil
+		return nil, err
 	}
-	req.Header.Add("Authorization", "Bearer "+c._tenant_access_token)
+	return NewRecordInfoWithoutModifiedTime(AppToken, TableId, record), nil
+}
 
-	res, err := client.Do(req)
+func (c AppClient) documentGetRecord(ctx context.Context, AppToken string, TableId string, RecordId string) (map[string]any, error) {
+	query := make(map[string]any)
+	query["automatic_fields"] = "true"
+	resp, err := c.RequestWithContext(ctx, "get", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/"+RecordId, query, nil, nil)
 	if err != nil {
-		logrus.Error(err)
-		return nil
+		return nil, err
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	record, ok := resp["record"].(map[string]any)
+	if !ok {
+		return nil, errors.New("record is missing in response")
+	}
+	return record, nil
+}
+
+func logDocumentRecordError(AppToken string, TableId string, RecordId string, err error) {
+	logrus.WithFields(logrus.Fields{
+		"AppToken": AppToken,
+		"TableID":  TableId,
+		"RecordID": RecordId,
+		"error":    err,
+	}).Warn("nil record info return")
+}
+
+// Get a []Byte form Record by AppToken, TableId and RecordId
+func (c AppClient) DocumentGetRecordInByte(AppToken string, TableId string, RecordId string) []byte {
+	body, err := c.DocumentGetRecordInByteWithError(AppToken, TableId, RecordId)
 	if err != nil {
 		logrus.Error(err)
 		return nil
 	}
-
 	return body
 }
 
+// Same as DocumentGetRecordInByte, the raw response is returned even if Feishu reports an error in it
+func (c AppClient) DocumentGetRecordInByteWithError(AppToken string, TableId string, RecordId string) ([]byte, error) {
+	return c.DocumentGetRecordInByteWithContext(context.Background(), AppToken, TableId, RecordId)
+}
+
+func (c AppClient) DocumentGetRecordInByteWithContext(ctx context.Context, AppToken string, TableId string, RecordId string) ([]byte, error) {
+	_, body, err := c.send(ctx, "get", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/"+RecordId, nil, nil, nil)
+	return body, err
+}
+
 type FieldStaff struct {
 	ID   string `json:"id,omitempty"`
 	Name string `json:"name,omitempty"`
@@ -231,35 +294,88 @@ type FieldStaff struct {
 
 // Create a record in bitable
 func (c AppClient) DocumentCreateRecord(AppToken string, TableId string, Fields map[string]any) *RecordInfo {
+	record, err := c.DocumentCreateRecordWithError(AppToken, TableId, Fields)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"AppToken": AppToken,
+			"TableID":  TableId,
+			"error":    err,
+		}).Error("create record fail")
+		return nil
+	}
+	return record
+}
+
+func (c AppClient) DocumentCreateRecordWithError(AppToken string, TableId string, Fields map[string]any) (*RecordInfo, error) {
+	return c.DocumentCreateRecordWithContext(context.Background(), AppToken, TableId, Fields)
+}
+
+func (c AppClient) DocumentCreateRecordWithContext(ctx context.Context, AppToken string, TableId string, Fields map[string]any) (*RecordInfo, error) {
 	body := make(map[string]any)
 	body["fields"] = Fields
 
-	resp := c.Request("post", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records", nil, nil, body)
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
 	logrus.Debug("Created a record: ", resp)
 
-	return NewRecordInfoWithoutModifiedTime(AppToken, TableId, resp["record"].(map[string]any))
+	record, ok := resp["record"].(map[string]any)
+	if !ok {
+		return nil, errors.New("record is missing in response")
+	}
+	return NewRecordInfoWithoutModifiedTime(AppToken, TableId, record), nil
 }
 
 // Update a record in bitable
 func (c AppClient) DocumentUpdateRecord(AppToken string, TableId string, RecordId string, Fields map[string]any) bool {
+	if err := c.DocumentUpdateRecordWithError(AppToken, TableId, RecordId, Fields); err != nil {
+		logDocumentRecordError(AppToken, TableId, RecordId, err)
+		return false
+	}
+	return true
+}
+
+func (c AppClient) DocumentUpdateRecordWithError(AppToken string, TableId string, RecordId string, Fields map[string]any) error {
+	return c.DocumentUpdateRecordWithContext(context.Background(), AppToken, TableId, RecordId, Fields)
+}
+
+func (c AppClient) DocumentUpdateRecordWithContext(ctx context.Context, AppToken string, TableId string, RecordId string, Fields map[string]any) error {
 	body := make(map[string]any)
 	body["fields"] = Fields
 
-	resp := c.Request("put", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/"+RecordId, nil, nil, body)
+	resp, err := c.RequestWithContext(ctx, "put", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/"+RecordId, nil, nil, body)
 	logrus.Debug("Updated record "+RecordId+": ", resp)
 
-	return true
+	return err
 }
 
 // Deleta records in bitable
 func (c AppClient) DocumentDeleteRecords(AppToken string, TableId string, RecordIds []string) bool {
+	if err := c.DocumentDeleteRecordsWithError(AppToken, TableId, RecordIds); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"AppToken":  AppToken,
+			"TableID":   TableId,
+			"RecordIDs": RecordIds,
+			"error":     err,
+		}).Error("delete records fail")
+		return false
+	}
+	return true
+}
+
+func (c AppClient) DocumentDeleteRecordsWithError(AppToken string, TableId string, RecordIds []string) error {
+	return c.DocumentDeleteRecordsWithContext(context.Background(), AppToken, TableId, RecordIds)
+}
+
+func (c AppClient) DocumentDeleteRecordsWithContext(ctx context.Context, AppToken string, TableId string, RecordIds []string) error {
 	body := make(map[string]any)
 	body["records"] = RecordIds
 
-	resp := c.Request("put", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/batch_delete", nil, nil, body)
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/bitable/v1/apps/"+AppToken+"/tables/"+TableId+"/records/batch_delete", nil, nil, body)
 	logrus.Debug("Deleted records : ", RecordIds, resp)
 
-	return true
+	return err
 }
 
 type TextStyle struct {
@@ -328,17 +444,49 @@ type BlockInfo struct {
 }
 
 func (c AppClient) DocumentGetAllBlocks(DocumentId string, userIdType UserIdType) []BlockInfo {
+	blocks, err := c.DocumentGetAllBlocksWithError(DocumentId, userIdType)
+	if err != nil {
+		logrus.WithField("DocumentID", DocumentId).WithError(err).Warn("nil block info return")
+		return []BlockInfo{}
+	}
+	return blocks
+}
+
+func (c AppClient) DocumentGetAllBlocksWithError(DocumentId string, userIdType UserIdType) ([]BlockInfo, error) {
+	return c.DocumentGetAllBlocksWithContext(context.Background(), DocumentId, userIdType)
+}
+
+func (c AppClient) DocumentGetAllBlocksWithContext(ctx context.Context, DocumentId string, userIdType UserIdType) ([]BlockInfo, error) {
 	query := make(map[string]any)
 	query["user_id_type"] = string(userIdType)
 
-	l := c.GetAllPages("get", "open-apis/docx/v1/documents/"+DocumentId+"/blocks", query, nil, nil, 100)
-	b, _ := json.Marshal(l)
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/docx/v1/documents/"+DocumentId+"/blocks", query, nil, nil, 100)
+	if err != nil {
+		return nil, err
+	}
+
 	blocks := make([]BlockInfo, 0)
-	json.Unmarshal(b, &blocks)
-	return blocks
+	if err := struct2map(l, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
 }
 
 func (c AppClient) DocumentCreateBlock(DocumentId string, BlockId string, userIdType UserIdType, blocks []BlockCreate, index int) {
+	if err := c.DocumentCreateBlockWithError(DocumentId, BlockId, userIdType, blocks, index); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"DocumentID": DocumentId,
+			"BlockID":    BlockId,
+			"error":      err,
+		}).Error("create block fail")
+	}
+}
+
+func (c AppClient) DocumentCreateBlockWithError(DocumentId string, BlockId string, userIdType UserIdType, blocks []BlockCreate, index int) error {
+	return c.DocumentCreateBlockWithContext(context.Background(), DocumentId, BlockId, userIdType, blocks, index)
+}
+
+func (c AppClient) DocumentCreateBlockWithContext(ctx context.Context, DocumentId string, BlockId string, userIdType UserIdType, blocks []BlockCreate, index int) error {
 	query := make(map[string]any)
 	query["user_id_type"] = string(userIdType)
 
@@ -346,49 +494,138 @@ func (c AppClient) DocumentCreateBlock(DocumentId string, BlockId string, userId
 	body["children"] = blocks
 	body["index"] = index
 
-	c.Request("post", "open-apis/docx/v1/documents/"+DocumentId+"/blocks/"+BlockId+"/children", query, nil, body)
+	_, err := c.RequestWithContext(ctx, "post", "open-apis/docx/v1/documents/"+DocumentId+"/blocks/"+BlockId+"/children", query, nil, body)
+	return err
 }
 
 func (c AppClient) DocumentUpdateBlock(DocumentId string, BlockId string, userIdType UserIdType, update *BlockUpdate) {
+	if err := c.DocumentUpdateBlockWithError(DocumentId, BlockId, userIdType, update); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"DocumentID": DocumentId,
+			"BlockID":    BlockId,
+			"error":      err,
+		}).Error("update block fail")
+	}
+}
+
+func (c AppClient) DocumentUpdateBlockWithError(DocumentId string, BlockId string, userIdType UserIdType, update *BlockUpdate) error {
+	return c.DocumentUpdateBlockWithContext(context.Background(), DocumentId, BlockId, userIdType, update)
+}
+
+func (c AppClient) DocumentUpdateBlockWithContext(ctx context.Context, DocumentId string, BlockId string, userIdType UserIdType, update *BlockUpdate) error {
 	query := make(map[string]any)
 	query["user_id_type"] = string(userIdType)
 
 	body := make(map[string]any)
-	struct2map(update, &body)
+	if err := struct2map(update, &body); err != nil {
+		return err
+	}
 
-	c.Request("patch", "open-apis/docx/v1/documents/"+DocumentId+"/blocks/"+BlockId, query, nil, body)
+	_, err := c.RequestWithContext(ctx, "patch", "open-apis/docx/v1/documents/"+DocumentId+"/blocks/"+BlockId, query, nil, body)
+	return err
 }
 
 func (c AppClient) DocumentGetRawContent(DocumentId string) string {
-	resp := c.Request("get", "open-apis/docx/v1/documents/"+DocumentId+"/raw_content", nil, nil, nil)
-	content := resp["content"].(string)
-
+	content, err := c.DocumentGetRawContentWithError(DocumentId)
+	if err != nil {
+		logrus.WithField("DocumentID", DocumentId).WithError(err).Error("get raw content fail")
+		return ""
+	}
 	return content
 }
 
+func (c AppClient) DocumentGetRawContentWithError(DocumentId string) (string, error) {
+	return c.DocumentGetRawContentWithContext(context.Background(), DocumentId)
+}
+
+func (c AppClient) DocumentGetRawContentWithContext(ctx context.Context, DocumentId string) (string, error) {
+	resp, err := c.RequestWithContext(ctx, "get", "open-apis/docx/v1/documents/"+DocumentId+"/raw_content", nil, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	content, _ := resp["content"].(string)
+
+	return content, nil
+}
+
 // Append data to a sheet in a spreadsheet, return the actual range of the appended data
 func (c AppClient) SheetAppendData(SpreadSheetToken string, SheetId string, Range string, Data [][]interface{}) string {
+	tableRange, err := c.SheetAppendDataWithError(SpreadSheetToken, SheetId, Range, Data)
+	if err != nil {
+		logSheetError(SpreadSheetToken, SheetId, Range, err)
+		return ""
+	}
+	return tableRange
+}
+
+func (c AppClient) SheetAppendDataWithError(SpreadSheetToken string, SheetId string, Range string, Data [][]interface{}) (string, error) {
+	return c.SheetAppendDataWithContext(context.Background(), SpreadSheetToken, SheetId, Range, Data)
+}
+
+func (c AppClient) SheetAppendDataWithContext(ctx context.Context, SpreadSheetToken string, SheetId string, Range string, Data [][]interface{}) (string, error) {
 	body := make(map[string]interface{})
 	body["range"] = SheetId + "!" + Range
 	body["values"] = Data
 	abody := make(map[string]interface{})
 	abody["valueRange"] = body
 
-	resp := c.Request("post", "open-apis/sheet/v2/spreadsheets/"+SpreadSheetToken+"/values_append", nil, nil, abody)
-	return resp["tableRange"].(string)
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/sheet/v2/spreadsheets/"+SpreadSheetToken+"/values_append", nil, nil, abody)
+	if err != nil {
+		return "", err
+	}
+	tableRange, _ := resp["tableRange"].(string)
+	return tableRange, nil
 }
 
 func (c AppClient) SheetGetData(SpreadSheetToken string, SheetId string, Range string) []interface{} {
-	resp := c.Request("get", "open-apis/sheet/v2/spreadsheets/"+SpreadSheetToken+"/values/"+SheetId+"!"+Range, nil, nil, nil)
-	return resp["valueRange"].(map[string]interface{})["values"].([]interface{})
+	values, err := c.SheetGetDataWithError(SpreadSheetToken, SheetId, Range)
+	if err != nil {
+		logSheetError(SpreadSheetToken, SheetId, Range, err)
+		return nil
+	}
+	return values
+}
+
+func (c AppClient) SheetGetDataWithError(SpreadSheetToken string, SheetId string, Range string) ([]interface{}, error) {
+	return c.SheetGetDataWithContext(context.Background(), SpreadSheetToken, SheetId, Range)
+}
+
+func (c AppClient) SheetGetDataWithContext(ctx context.Context, SpreadSheetToken string, SheetId string, Range string) ([]interface{}, error) {
+	resp, err := c.RequestWithContext(ctx, "get", "open-apis/sheet/v2/spreadsheets/"+SpreadSheetToken+"/values/"+SheetId+"!"+Range, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	valueRange, _ := resp["valueRange"].(map[string]interface{})
+	values, _ := valueRange["values"].([]interface{})
+	return values, nil
 }
 
 func (c AppClient) SheetWriteData(SpreadSheetToken string, SheetId string, Range string, Data [][]interface{}) {
+	if err := c.SheetWriteDataWithError(SpreadSheetToken, SheetId, Range, Data); err != nil {
+		logSheetError(SpreadSheetToken, SheetId, Range, err)
+	}
+}
+
+func (c AppClient) SheetWriteDataWithError(SpreadSheetToken string, SheetId string, Range string, Data [][]interface{}) error {
+	return c.SheetWriteDataWithContext(context.Background(), SpreadSheetToken, SheetId, Range, Data)
+}
+
+func (c AppClient) SheetWriteDataWithContext(ctx context.Context, SpreadSheetToken string, SheetId string, Range string, Data [][]interface{}) error {
 	body := make(map[string]interface{})
 	body["range"] = SheetId + "!" + Range
 	body["values"] = Data
 	abody := make(map[string]interface{})
 	abody["valueRange"] = body
 
-	c.Request("put", "open-apis/sheet/v2/spreadsheets/"+SpreadSheetToken+"/values", nil, nil, abody)
+	_, err := c.RequestWithContext(ctx, "put", "open-apis/sheet/v2/spreadsheets/"+SpreadSheetToken+"/values", nil, nil, abody)
+	return err
+}
+
+func logSheetError(SpreadSheetToken string, SheetId string, Range string, err error) {
+	logrus.WithFields(logrus.Fields{
+		"SpreadSheetToken": SpreadSheetToken,
+		"SheetID":          SheetId,
+		"Range":            Range,
+		"error":            err,
+	}).Error("sheet request fail")
 }
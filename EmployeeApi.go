@@ -1,6 +1,10 @@
 package feishuapi
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
 
 type EmployeeType int
 
@@ -52,30 +56,50 @@ const (
 
 // Get all employees' information by specific user id type
 func (c AppClient) EmployeeGetAllInfo(id_type UserIdType) []EmployeeInfo {
-	query := make(map[string]any)
-	query["user_id_type"] = string(id_type)
-	l := c.GetAllPages("get", "open-apis/ehr/v1/employees", query, nil, nil, 100)
-	if l == nil {
-		logrus.Warn("nil employee info return")
+	all_employees, err := c.EmployeeGetAllInfoWithError(id_type)
+	if err != nil {
+		logrus.WithError(err).Warn("nil employee info return")
 		return nil
 	}
+	return all_employees
+}
 
-	var all_employees []EmployeeInfo
-	for _, value := range l {
-		all_employees = append(all_employees, *NewEmployeeInfo(value.(map[string]any)))
-	}
+func (c AppClient) EmployeeGetAllInfoWithError(id_type UserIdType) ([]EmployeeInfo, error) {
+	return c.EmployeeGetAllInfoWithContext(context.Background(), id_type)
+}
 
-	return all_employees
+func (c AppClient) EmployeeGetAllInfoWithContext(ctx context.Context, id_type UserIdType) ([]EmployeeInfo, error) {
+	query := make(map[string]any)
+	query["user_id_type"] = string(id_type)
+
+	return c.employeeGetInfo(ctx, query)
 }
 
 func (c AppClient) EmployeeGetInfo(id_type UserIdType, id []string) []EmployeeInfo {
+	employees, err := c.EmployeeGetInfoWithError(id_type, id)
+	if err != nil {
+		logrus.WithError(err).Warn("nil employee info return")
+		return nil
+	}
+	return employees
+}
+
+func (c AppClient) EmployeeGetInfoWithError(id_type UserIdType, id []string) ([]EmployeeInfo, error) {
+	return c.EmployeeGetInfoWithContext(context.Background(), id_type, id)
+}
+
+func (c AppClient) EmployeeGetInfoWithContext(ctx context.Context, id_type UserIdType, id []string) ([]EmployeeInfo, error) {
 	query := make(map[string]interface{})
 	query["user_id_type"] = string(id_type)
 	query["user_ids"] = id
-	l := c.GetAllPages("get", "open-apis/ehr/v1/employees", query, nil, nil, 100)
-	if l == nil {
-		logrus.Warn("nil employee info return")
-		return nil
+
+	return c.employeeGetInfo(ctx, query)
+}
+
+func (c AppClient) employeeGetInfo(ctx context.Context, query map[string]any) ([]EmployeeInfo, error) {
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/ehr/v1/employees", query, nil, nil, 100)
+	if err != nil {
+		return nil, err
 	}
 
 	var employees []EmployeeInfo
@@ -83,5 +107,5 @@ func (c AppClient) EmployeeGetInfo(id_type UserIdType, id []string) []EmployeeIn
 		employees = append(employees, *NewEmployeeInfo(value.(map[string]any)))
 	}
 
-	return employees
+	return employees, nil
 }
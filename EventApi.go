@@ -0,0 +1,270 @@
+package feishuapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event is an event pushed by Feishu, the v1.0 and v2.0 envelopes are both normalized into it
+type Event struct {
+	// "1.0" or "2.0"
+	Schema     string
+	EventId    string
+	EventType  string
+	CreateTime time.Time
+	Token      string
+	AppId      string
+	TenantKey  string
+	// the "event" object of the envelope, decode it into the struct of EventType
+	Event json.RawMessage
+}
+
+// EventHandler handles an event, Feishu pushes the event again later if it returns an error
+type EventHandler func(ctx context.Context, event *Event) error
+
+var (
+	ErrInvalidEventSignature = errors.New("invalid event signature")
+	ErrInvalidEventToken     = errors.New("invalid event verification token")
+)
+
+const maxEventBodySize = 10 << 20
+
+// EventWebhookHandler receives the events which are subscribed by the request url of the app.
+// It answers the url_verification challenge, decrypts the events with Conf.EncryptKey,
+// checks the signature and Conf.VerificationToken, then hands the events to handle
+func (c AppClient) EventWebhookHandler(handle EventHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxEventBodySize))
+		if err != nil {
+			http.Error(w, "read body fail", http.StatusBadRequest)
+			return
+		}
+
+		payload, challenge, err := c.openEventPayload(r.Header, body)
+		if err != nil {
+			logrus.WithError(err).Warn("reject feishu event")
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrInvalidEventSignature) || errors.Is(err, ErrInvalidEventToken) {
+				status = http.StatusUnauthorized
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if challenge != "" {
+			writeEventJSON(w, map[string]string{"challenge": challenge})
+			return
+		}
+
+		event, err := parseEvent(payload)
+		if err != nil {
+			logrus.WithError(err).Warn("parse feishu event fail")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := c.checkEventToken(event.Token); err != nil {
+			logrus.WithField("EventID", event.EventId).WithError(err).Warn("reject feishu event")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		if err := handle(r.Context(), event); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"EventID":   event.EventId,
+				"EventType": event.EventType,
+			}).WithError(err).Error("handle feishu event fail")
+			http.Error(w, "handle event fail", http.StatusInternalServerError)
+			return
+		}
+		writeEventJSON(w, map[string]string{})
+	})
+}
+
+// Verify and decrypt the request body, return the plain payload, or the challenge of a url_verification request
+func (c AppClient) openEventPayload(header http.Header, body []byte) ([]byte, string, error) {
+	var encrypted struct {
+		Encrypt string `json:"encrypt"`
+	}
+	if err := json.Unmarshal(body, &encrypted); err != nil {
+		return nil, "", fmt.Errorf("decode event body: %w", err)
+	}
+
+	// the url_verification request isn't signed, the others are signed when EncryptKey is configured
+	signed := header.Get("X-Lark-Signature") != ""
+	if signed && c.Conf.EncryptKey != "" {
+		if err := c.checkEventSignature(header, body); err != nil {
+			return nil, "", err
+		}
+	}
+
+	payload := body
+	if encrypted.Encrypt != "" {
+		if c.Conf.EncryptKey == "" {
+			return nil, "", errors.New("event is encrypted but EncryptKey is not configured")
+		}
+		var err error
+		payload, err = decryptEvent(c.Conf.EncryptKey, encrypted.Encrypt)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	var verification struct {
+		Type      string `json:"type"`
+		Token     string `json:"token"`
+		Challenge string `json:"challenge"`
+	}
+	if err := json.Unmarshal(payload, &verification); err != nil {
+		return nil, "", fmt.Errorf("decode event payload: %w", err)
+	}
+	if verification.Type == "url_verification" {
+		if err := c.checkEventToken(verification.Token); err != nil {
+			return nil, "", err
+		}
+		return nil, verification.Challenge, nil
+	}
+	if c.Conf.EncryptKey != "" && !signed {
+		return nil, "", ErrInvalidEventSignature
+	}
+	return payload, "", nil
+}
+
+// The signature is sha256(timestamp + nonce + encrypt_key + body) in hex
+func (c AppClient) checkEventSignature(header http.Header, body []byte) error {
+	h := sha256.New()
+	h.Write([]byte(header.Get("X-Lark-Request-Timestamp")))
+	h.Write([]byte(header.Get("X-Lark-Request-Nonce")))
+	h.Write([]byte(c.Conf.EncryptKey))
+	h.Write(body)
+	expected := hex.EncodeToString(h.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(header.Get("X-Lark-Signature"))) != 1 {
+		return ErrInvalidEventSignature
+	}
+	return nil
+}
+
+func (c AppClient) checkEventToken(token string) error {
+	if c.Conf.VerificationToken == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Conf.VerificationToken), []byte(token)) != 1 {
+		return ErrInvalidEventToken
+	}
+	return nil
+}
+
+// Decrypt the "encrypt" field, which is base64(iv + AES-256-CBC(payload)) with sha256(encryptKey) as the key
+func decryptEvent(encryptKey string, encrypt string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("decode encrypted event: %w", err)
+	}
+	if len(data) < aes.BlockSize*2 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid encrypted event length")
+	}
+
+	key := sha256.Sum256([]byte(encryptKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	iv, data := data[:aes.BlockSize], data[aes.BlockSize:]
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	// remove the PKCS#7 padding
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("invalid encrypted event padding, check EncryptKey")
+	}
+	return plain[:len(plain)-padding], nil
+}
+
+// Parse a v1.0 or v2.0 event envelope
+func parseEvent(payload []byte) (*Event, error) {
+	var envelope struct {
+		Schema string `json:"schema"`
+		Header struct {
+			EventId    string `json:"event_id"`
+			EventType  string `json:"event_type"`
+			CreateTime string `json:"create_time"`
+			Token      string `json:"token"`
+			AppId      string `json:"app_id"`
+			TenantKey  string `json:"tenant_key"`
+		} `json:"header"`
+
+		// v1.0
+		Uuid  string          `json:"uuid"`
+		Token string          `json:"token"`
+		Ts    string          `json:"ts"`
+		Type  string          `json:"type"`
+		Event json.RawMessage `json:"event"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, fmt.Errorf("decode event envelope: %w", err)
+	}
+
+	if envelope.Schema != "" {
+		event := &Event{
+			Schema:    envelope.Schema,
+			EventId:   envelope.Header.EventId,
+			EventType: envelope.Header.EventType,
+			Token:     envelope.Header.Token,
+			AppId:     envelope.Header.AppId,
+			TenantKey: envelope.Header.TenantKey,
+			Event:     envelope.Event,
+		}
+		if ms, err := strconv.ParseInt(envelope.Header.CreateTime, 10, 64); err == nil {
+			event.CreateTime = time.UnixMilli(ms)
+		}
+		return event, nil
+	}
+
+	if envelope.Type != "event_callback" {
+		return nil, fmt.Errorf("unknown event type %q", envelope.Type)
+	}
+	var inner struct {
+		Type      string `json:"type"`
+		AppId     string `json:"app_id"`
+		TenantKey string `json:"tenant_key"`
+	}
+	if err := json.Unmarshal(envelope.Event, &inner); err != nil {
+		return nil, fmt.Errorf("decode event: %w", err)
+	}
+	event := &Event{
+		Schema:    "1.0",
+		EventId:   envelope.Uuid,
+		EventType: inner.Type,
+		Token:     envelope.Token,
+		AppId:     inner.AppId,
+		TenantKey: inner.TenantKey,
+		Event:     envelope.Event,
+	}
+	// ts is in seconds with a fraction, like "1502199207.7171419"
+	if sec, err := strconv.ParseFloat(strings.TrimSpace(envelope.Ts), 64); err == nil {
+		event.CreateTime = time.UnixMilli(int64(sec * 1000))
+	}
+	return event, nil
+}
+
+func writeEventJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
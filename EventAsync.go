@@ -0,0 +1,242 @@
+package feishuapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventSeenStore remembers the ids of the handled events, so that the events redelivered by Feishu are dropped
+type EventSeenStore interface {
+	// Record the event id for ttl, report whether it is the first time the id is seen
+	MarkSeen(ctx context.Context, eventId string, ttl time.Duration) (bool, error)
+	// Forget the event id, so that its redelivery is handled
+	Forget(ctx context.Context, eventId string) error
+}
+
+// EventMetrics receives the measurements of AsyncEventHandler
+type EventMetrics interface {
+	// Called after the handler returns, err is what it returns
+	ObserveEventHandled(eventType string, duration time.Duration, err error)
+	// Called when an event isn't handled, reason is "duplicate" or "queue_full"
+	ObserveEventDropped(eventType string, reason string)
+}
+
+// MemorySeenStore keeps the event ids in memory, it only dedupes the events received by one process
+type MemorySeenStore struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastPurge time.Time
+}
+
+func NewMemorySeenStore() *MemorySeenStore {
+	return &MemorySeenStore{
+		seen: make(map[string]time.Time),
+	}
+}
+
+const memorySeenStorePurgeInterval = time.Minute
+
+func (s *MemorySeenStore) MarkSeen(ctx context.Context, eventId string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPurge) > memorySeenStorePurgeInterval {
+		for id, expireAt := range s.seen {
+			if !now.Before(expireAt) {
+				delete(s.seen, id)
+			}
+		}
+		s.lastPurge = now
+	}
+
+	if expireAt, ok := s.seen[eventId]; ok && now.Before(expireAt) {
+		return false, nil
+	}
+	s.seen[eventId] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemorySeenStore) Forget(ctx context.Context, eventId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.seen, eventId)
+	return nil
+}
+
+// AsyncEventOptions configures AsyncEventHandler, the zero values mean the defaults
+type AsyncEventOptions struct {
+	// the number of the handlers running at the same time, 8 by default
+	Workers int
+	// the number of the events waiting for a worker, 1024 by default.
+	// The events beyond it are rejected, so that Feishu delivers them again later
+	QueueSize int
+	// NewMemorySeenStore() by default
+	SeenStore EventSeenStore
+	// how long an event id is remembered, 12 hours by default, which covers the redelivery of Feishu
+	SeenTTL time.Duration
+	// the timeout of one handler call, no timeout by default
+	Timeout time.Duration
+	Metrics EventMetrics
+}
+
+const (
+	defaultEventWorkers   = 8
+	defaultEventQueueSize = 1024
+	defaultEventSeenTTL   = time.Hour * 12
+)
+
+var ErrEventQueueFull = errors.New("event queue is full")
+
+// AsyncEventHandler acknowledges the events at once and handles them on a bounded worker pool,
+// the events with the same event_id (uuid for v1.0 events) are handled only once.
+// Since the events are acknowledged before they are handled, Feishu doesn't deliver the failed ones again
+type AsyncEventHandler struct {
+	handle EventHandler
+	opts   AsyncEventOptions
+
+	queue   chan *Event
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewAsyncEventHandler(handle EventHandler, opts AsyncEventOptions) *AsyncEventHandler {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultEventWorkers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultEventQueueSize
+	}
+	if opts.SeenStore == nil {
+		opts.SeenStore = NewMemorySeenStore()
+	}
+	if opts.SeenTTL <= 0 {
+		opts.SeenTTL = defaultEventSeenTTL
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &AsyncEventHandler{
+		handle: handle,
+		opts:   opts,
+		queue:  make(chan *Event, opts.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for i := 0; i < opts.Workers; i++ {
+		h.workers.Add(1)
+		go h.work()
+	}
+	return h
+}
+
+// Handle is an EventHandler which queues the event and returns at once
+func (h *AsyncEventHandler) Handle(ctx context.Context, event *Event) error {
+	if event.EventId != "" {
+		first, err := h.opts.SeenStore.MarkSeen(ctx, event.EventId, h.opts.SeenTTL)
+		if err != nil {
+			// handling an event twice is better than losing it
+			logrus.WithField("EventID", event.EventId).WithError(err).Warn("check duplicated feishu event fail")
+		} else if !first {
+			h.dropped(event, "duplicate")
+			return nil
+		}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !h.closed {
+		select {
+		case h.queue <- event:
+			return nil
+		default:
+		}
+	}
+
+	h.dropped(event, "queue_full")
+	if event.EventId != "" {
+		if err := h.opts.SeenStore.Forget(ctx, event.EventId); err != nil {
+			logrus.WithField("EventID", event.EventId).WithError(err).Warn("forget feishu event fail")
+		}
+	}
+	return ErrEventQueueFull
+}
+
+// Stop accepting events and wait for the queued ones to be handled.
+// The running handlers are cancelled if ctx is done before they finish
+func (h *AsyncEventHandler) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		h.cancel()
+		return nil
+	case <-ctx.Done():
+		h.cancel()
+		return ctx.Err()
+	}
+}
+
+func (h *AsyncEventHandler) work() {
+	defer h.workers.Done()
+	for event := range h.queue {
+		h.run(event)
+	}
+}
+
+func (h *AsyncEventHandler) run(event *Event) {
+	ctx := h.ctx
+	if h.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.WithField("EventID", event.EventId).WithField("panic", r).Error("feishu event handler panic")
+			err = errors.New("event handler panic")
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"EventID":   event.EventId,
+				"EventType": event.EventType,
+			}).WithError(err).Error("handle feishu event fail")
+		}
+		if h.opts.Metrics != nil {
+			h.opts.Metrics.ObserveEventHandled(event.EventType, time.Since(start), err)
+		}
+	}()
+	err = h.handle(ctx, event)
+}
+
+func (h *AsyncEventHandler) dropped(event *Event, reason string) {
+	logrus.WithFields(logrus.Fields{
+		"EventID":   event.EventId,
+		"EventType": event.EventType,
+		"reason":    reason,
+	}).Debug("drop feishu event")
+	if h.opts.Metrics != nil {
+		h.opts.Metrics.ObserveEventDropped(event.EventType, reason)
+	}
+}
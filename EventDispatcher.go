@@ -0,0 +1,376 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The event types which EventDispatcher has typed handlers for
+const (
+	EventTypeMessageReceive         = "im.message.receive_v1"
+	EventTypeChatMemberAdded        = "im.chat.member.user.added_v1"
+	EventTypeUserCreated            = "contact.user.created_v3"
+	EventTypeApprovalInstanceStatus = "approval_instance"
+	EventTypeBitableRecordChanged   = "drive.file.bitable_record_changed_v1"
+)
+
+// EventDispatcher routes the events to the handlers which are registered for their types.
+// Use Dispatch as the EventHandler of EventWebhookHandler
+type EventDispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string]EventHandler
+	fallback EventHandler
+}
+
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{
+		handlers: make(map[string]EventHandler),
+	}
+}
+
+// Register the handler of the raw events of eventType, it replaces the handler registered before
+func (d *EventDispatcher) On(eventType string, handler EventHandler) *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = handler
+	return d
+}
+
+// Register the handler of the events which have no handler, they are dropped if it is nil
+func (d *EventDispatcher) OnUnknown(handler EventHandler) *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fallback = handler
+	return d
+}
+
+func (d *EventDispatcher) Dispatch(ctx context.Context, event *Event) error {
+	d.mu.RLock()
+	handler, ok := d.handlers[event.EventType]
+	if !ok {
+		handler = d.fallback
+	}
+	d.mu.RUnlock()
+
+	if handler == nil {
+		logrus.WithFields(logrus.Fields{
+			"EventID":   event.EventId,
+			"EventType": event.EventType,
+		}).Debug("no handler for feishu event")
+		return nil
+	}
+	return handler(ctx, event)
+}
+
+// Decode the event with decode, and hand the typed event to handle
+func onTypedEvent[T any](d *EventDispatcher, eventType string, decode func(event *Event) (*T, error), handle func(ctx context.Context, event *T) error) *EventDispatcher {
+	return d.On(eventType, func(ctx context.Context, event *Event) error {
+		typed, err := decode(event)
+		if err != nil {
+			return fmt.Errorf("decode %s event: %w", eventType, err)
+		}
+		return handle(ctx, typed)
+	})
+}
+
+// EventUserId is the ids of a user in the events
+type EventUserId struct {
+	UnionId string `json:"union_id"`
+	UserId  string `json:"user_id"`
+	OpenId  string `json:"open_id"`
+}
+
+// parse the millisecond timestamps in the events and the responses
+func millisTime(ms string) time.Time {
+	v, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(v)
+}
+
+type MessageMention struct {
+	Key       string
+	Id        EventUserId
+	Name      string
+	TenantKey string
+}
+
+type EventMessage struct {
+	MessageId   string
+	RootId      string
+	ParentId    string
+	CreateTime  time.Time
+	ChatId      string
+	ChatType    string
+	MessageType string
+	// the content in JSON, its format depends on MessageType
+	Content  string
+	Mentions []MessageMention
+}
+
+type MessageReceiveEvent struct {
+	Header     *Event
+	Sender     EventUserId
+	SenderType string
+	Message    EventMessage
+}
+
+func newMessageReceiveEvent(event *Event) (*MessageReceiveEvent, error) {
+	var data struct {
+		Sender struct {
+			SenderId   EventUserId `json:"sender_id"`
+			SenderType string      `json:"sender_type"`
+		} `json:"sender"`
+		Message struct {
+			MessageId   string `json:"message_id"`
+			RootId      string `json:"root_id"`
+			ParentId    string `json:"parent_id"`
+			CreateTime  string `json:"create_time"`
+			ChatId      string `json:"chat_id"`
+			ChatType    string `json:"chat_type"`
+			MessageType string `json:"message_type"`
+			Content     string `json:"content"`
+			Mentions    []struct {
+				Key       string      `json:"key"`
+				Id        EventUserId `json:"id"`
+				Name      string      `json:"name"`
+				TenantKey string      `json:"tenant_key"`
+			} `json:"mentions"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(event.Event, &data); err != nil {
+		return nil, err
+	}
+
+	var mentions []MessageMention
+	for _, v := range data.Message.Mentions {
+		mentions = append(mentions, MessageMention{Key: v.Key, Id: v.Id, Name: v.Name, TenantKey: v.TenantKey})
+	}
+	return &MessageReceiveEvent{
+		Header:     event,
+		Sender:     data.Sender.SenderId,
+		SenderType: data.Sender.SenderType,
+		Message: EventMessage{
+			MessageId:   data.Message.MessageId,
+			RootId:      data.Message.RootId,
+			ParentId:    data.Message.ParentId,
+			CreateTime:  millisTime(data.Message.CreateTime),
+			ChatId:      data.Message.ChatId,
+			ChatType:    data.Message.ChatType,
+			MessageType: data.Message.MessageType,
+			Content:     data.Message.Content,
+			Mentions:    mentions,
+		},
+	}, nil
+}
+
+// Handle the messages which are sent to the bot, or mention it in a group
+func (d *EventDispatcher) OnMessageReceive(handler func(ctx context.Context, event *MessageReceiveEvent) error) *EventDispatcher {
+	return onTypedEvent(d, EventTypeMessageReceive, newMessageReceiveEvent, handler)
+}
+
+type ChatMemberAddedEvent struct {
+	Header   *Event
+	Chat     GroupInfo
+	Operator EventUserId
+	// only Name, UnionId, UserId and OpenId are set
+	Users []UserInfo
+}
+
+func newChatMemberAddedEvent(event *Event) (*ChatMemberAddedEvent, error) {
+	var data struct {
+		ChatId     string      `json:"chat_id"`
+		Name       string      `json:"name"`
+		OperatorId EventUserId `json:"operator_id"`
+		Users      []struct {
+			Name   string      `json:"name"`
+			UserId EventUserId `json:"user_id"`
+		} `json:"users"`
+	}
+	if err := json.Unmarshal(event.Event, &data); err != nil {
+		return nil, err
+	}
+
+	var users []UserInfo
+	for _, v := range data.Users {
+		users = append(users, UserInfo{
+			UnionId: v.UserId.UnionId,
+			OpenId:  v.UserId.OpenId,
+			UserId:  v.UserId.UserId,
+			Name:    v.Name,
+		})
+	}
+	return &ChatMemberAddedEvent{
+		Header: event,
+		Chat: GroupInfo{
+			ChatId:    data.ChatId,
+			Name:      data.Name,
+			TenantKey: event.TenantKey,
+		},
+		Operator: data.OperatorId,
+		Users:    users,
+	}, nil
+}
+
+// Handle the users who join a group which the bot is in
+func (d *EventDispatcher) OnChatMemberAdded(handler func(ctx context.Context, event *ChatMemberAddedEvent) error) *EventDispatcher {
+	return onTypedEvent(d, EventTypeChatMemberAdded, newChatMemberAddedEvent, handler)
+}
+
+type UserCreatedEvent struct {
+	Header *Event
+	User   UserInfo
+}
+
+func newUserCreatedEvent(event *Event) (*UserCreatedEvent, error) {
+	var data struct {
+		Object struct {
+			UnionId       string        `json:"union_id"`
+			OpenId        string        `json:"open_id"`
+			UserId        string        `json:"user_id"`
+			Name          string        `json:"name"`
+			DepartmentIds []interface{} `json:"department_ids"`
+		} `json:"object"`
+	}
+	if err := json.Unmarshal(event.Event, &data); err != nil {
+		return nil, err
+	}
+	return &UserCreatedEvent{
+		Header: event,
+		User: UserInfo{
+			UnionId:       data.Object.UnionId,
+			OpenId:        data.Object.OpenId,
+			UserId:        data.Object.UserId,
+			Name:          data.Object.Name,
+			DepartmentIds: data.Object.DepartmentIds,
+		},
+	}, nil
+}
+
+// Handle the employees who join the tenant
+func (d *EventDispatcher) OnUserCreated(handler func(ctx context.Context, event *UserCreatedEvent) error) *EventDispatcher {
+	return onTypedEvent(d, EventTypeUserCreated, newUserCreatedEvent, handler)
+}
+
+type ApprovalInstanceStatusChangedEvent struct {
+	Header       *Event
+	ApprovalCode string
+	InstanceCode string
+	// PENDING, APPROVED, REJECTED, CANCELED or DELETED
+	Status      string
+	OperateTime time.Time
+}
+
+func newApprovalInstanceStatusChangedEvent(event *Event) (*ApprovalInstanceStatusChangedEvent, error) {
+	var data struct {
+		ApprovalCode string `json:"approval_code"`
+		InstanceCode string `json:"instance_code"`
+		Status       string `json:"status"`
+		OperateTime  string `json:"operate_time"`
+	}
+	if err := json.Unmarshal(event.Event, &data); err != nil {
+		return nil, err
+	}
+	return &ApprovalInstanceStatusChangedEvent{
+		Header:       event,
+		ApprovalCode: data.ApprovalCode,
+		InstanceCode: data.InstanceCode,
+		Status:       data.Status,
+		OperateTime:  millisTime(data.OperateTime),
+	}, nil
+}
+
+// Get the full info of the approval instance, the event only carries its status
+func (e *ApprovalInstanceStatusChangedEvent) Instance(ctx context.Context, c AppClient) (*ApprovalInstanceInfo, error) {
+	return c.ApprovalInstanceByIdWithContext(ctx, e.InstanceCode)
+}
+
+// Handle the status changes of the approval instances, the approval must be subscribed first
+func (d *EventDispatcher) OnApprovalInstanceStatusChanged(handler func(ctx context.Context, event *ApprovalInstanceStatusChangedEvent) error) *EventDispatcher {
+	return onTypedEvent(d, EventTypeApprovalInstanceStatus, newApprovalInstanceStatusChangedEvent, handler)
+}
+
+type BitableFieldValue struct {
+	FieldId string
+	// the value in JSON
+	FieldValue string
+}
+
+type BitableRecordAction struct {
+	RecordId string
+	// record_added, record_deleted or record_edited
+	Action      string
+	BeforeValue []BitableFieldValue
+	AfterValue  []BitableFieldValue
+}
+
+type BitableRecordChangedEvent struct {
+	Header     *Event
+	FileToken  string
+	TableId    string
+	Revision   int
+	Operator   EventUserId
+	Actions    []BitableRecordAction
+	UpdateTime time.Time
+}
+
+func newBitableRecordChangedEvent(event *Event) (*BitableRecordChangedEvent, error) {
+	type fieldValue struct {
+		FieldId    string `json:"field_id"`
+		FieldValue string `json:"field_value"`
+	}
+	var data struct {
+		FileToken  string      `json:"file_token"`
+		TableId    string      `json:"table_id"`
+		Revision   int         `json:"revision"`
+		OperatorId EventUserId `json:"operator_id"`
+		ActionList []struct {
+			RecordId    string       `json:"record_id"`
+			Action      string       `json:"action"`
+			BeforeValue []fieldValue `json:"before_value"`
+			AfterValue  []fieldValue `json:"after_value"`
+		} `json:"action_list"`
+		UpdateTime int64 `json:"update_time"`
+	}
+	if err := json.Unmarshal(event.Event, &data); err != nil {
+		return nil, err
+	}
+
+	convert := func(values []fieldValue) []BitableFieldValue {
+		var result []BitableFieldValue
+		for _, v := range values {
+			result = append(result, BitableFieldValue{FieldId: v.FieldId, FieldValue: v.FieldValue})
+		}
+		return result
+	}
+	var actions []BitableRecordAction
+	for _, v := range data.ActionList {
+		actions = append(actions, BitableRecordAction{
+			RecordId:    v.RecordId,
+			Action:      v.Action,
+			BeforeValue: convert(v.BeforeValue),
+			AfterValue:  convert(v.AfterValue),
+		})
+	}
+	return &BitableRecordChangedEvent{
+		Header:     event,
+		FileToken:  data.FileToken,
+		TableId:    data.TableId,
+		Revision:   data.Revision,
+		Operator:   data.OperatorId,
+		Actions:    actions,
+		UpdateTime: time.Unix(data.UpdateTime, 0),
+	}, nil
+}
+
+// Handle the record changes of a bitable, the bitable must be subscribed first
+func (d *EventDispatcher) OnBitableRecordChanged(handler func(ctx context.Context, event *BitableRecordChangedEvent) error) *EventDispatcher {
+	return onTypedEvent(d, EventTypeBitableRecordChanged, newBitableRecordChangedEvent, handler)
+}
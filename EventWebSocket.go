@@ -0,0 +1,382 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWebSocketPingInterval     = time.Minute * 2
+	defaultWebSocketReconnectMaxWait = time.Minute * 2
+	webSocketReconnectBaseWait       = time.Second
+	// the parts of a message which don't arrive in time are dropped
+	webSocketFragmentTTL = time.Second * 10
+	// the messages which are handled at the same time, the reading waits when all of them are busy
+	defaultWebSocketHandlers = 16
+)
+
+// WebSocketClient receives the events through the long connection, so that the bots without a public
+// endpoint can receive events. The events are handed to the same EventHandler as EventWebhookHandler
+type WebSocketClient struct {
+	client     AppClient
+	handle     EventHandler
+	cardHandle CardActionHandler
+	dialer     *websocket.Dialer
+	handlers   int
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	serviceId int32
+	config    webSocketClientConfig
+	fragments map[string]*webSocketFragments
+}
+
+type webSocketClientConfig struct {
+	ReconnectCount    int `json:"ReconnectCount"`
+	ReconnectInterval int `json:"ReconnectInterval"`
+	ReconnectNonce    int `json:"ReconnectNonce"`
+	PingInterval      int `json:"PingInterval"`
+}
+
+type webSocketFragments struct {
+	parts     [][]byte
+	createdAt time.Time
+}
+
+// Create a WebSocketClient with the credentials of c, the events are handled by handle
+func (c AppClient) NewWebSocketClient(handle EventHandler) *WebSocketClient {
+	return &WebSocketClient{
+		client:   c,
+		handle:   handle,
+		dialer:   websocket.DefaultDialer,
+		handlers: defaultWebSocketHandlers,
+		config:   webSocketClientConfig{ReconnectCount: -1},
+	}
+}
+
+// Set how many messages can be handled at the same time, 16 by default.
+// Feishu delivers a message again if it isn't acknowledged in time, so the reading waits instead of dropping it
+func (w *WebSocketClient) WithMaxHandlers(n int) *WebSocketClient {
+	if n > 0 {
+		w.handlers = n
+	}
+	return w
+}
+
+// Handle the card actions received through the long connection
+func (w *WebSocketClient) WithCardActionHandler(handle CardActionHandler) *WebSocketClient {
+	w.cardHandle = handle
+	return w
+}
+
+// Connect and receive the events until ctx is done, it reconnects with backoff when the connection is lost.
+// It returns the last error once the reconnect count which is given by Feishu runs out
+func (w *WebSocketClient) Start(ctx context.Context) error {
+	failures := 0
+	for {
+		connected, err := w.serve(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			failures = 0
+		}
+		failures++
+
+		w.mu.Lock()
+		config := w.config
+		w.mu.Unlock()
+		if config.ReconnectCount >= 0 && failures > config.ReconnectCount {
+			return err
+		}
+
+		delay := webSocketReconnectDelay(failures, config)
+		logrus.WithError(err).WithField("delay", delay).Warn("feishu websocket disconnected, reconnecting")
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// The first reconnection waits a random time within ReconnectNonce, so that the clients don't reconnect together.
+// Then the wait doubles until ReconnectInterval
+func webSocketReconnectDelay(failures int, config webSocketClientConfig) time.Duration {
+	if failures == 1 {
+		if config.ReconnectNonce > 0 {
+			return time.Duration(rand.Int63n(int64(time.Duration(config.ReconnectNonce) * time.Second)))
+		}
+		return 0
+	}
+
+	maxWait := defaultWebSocketReconnectMaxWait
+	if config.ReconnectInterval > 0 {
+		maxWait = time.Duration(config.ReconnectInterval) * time.Second
+	}
+	delay := webSocketReconnectBaseWait << uint(failures-2)
+	if delay <= 0 || delay > maxWait {
+		delay = maxWait
+	}
+	// jitter in the upper half
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Get the url of the long connection
+func (w *WebSocketClient) endpoint(ctx context.Context) (string, webSocketClientConfig, error) {
+	body := map[string]string{
+		"AppID":     w.client.Conf.AppId,
+		"AppSecret": w.client.Conf.AppSecret,
+	}
+	headers := map[string]string{"locale": "zh"}
+
+	data, err := w.client.RequestWithContext(ctx, "post", webSocketEndpointPath, nil, headers, body)
+	if err != nil {
+		return "", webSocketClientConfig{}, err
+	}
+
+	var result struct {
+		URL          string                `json:"URL"`
+		ClientConfig webSocketClientConfig `json:"ClientConfig"`
+	}
+	if err := map2struct(data, &result); err != nil {
+		return "", webSocketClientConfig{}, err
+	}
+	if result.URL == "" {
+		return "", webSocketClientConfig{}, errors.New("websocket url is missing in response")
+	}
+	return result.URL, result.ClientConfig, nil
+}
+
+// Run one connection, report whether it is established
+func (w *WebSocketClient) serve(ctx context.Context) (bool, error) {
+	endpoint, config, err := w.endpoint(ctx)
+	if err != nil {
+		return false, fmt.Errorf("get websocket endpoint: %w", err)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false, fmt.Errorf("parse websocket endpoint: %w", err)
+	}
+	serviceId, _ := strconv.ParseInt(u.Query().Get("service_id"), 10, 32)
+
+	conn, resp, err := w.dialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		if resp != nil {
+			return false, fmt.Errorf("dial websocket: %w, status %d, %s", err, resp.StatusCode, resp.Header.Get("Handshake-Msg"))
+		}
+		return false, fmt.Errorf("dial websocket: %w", err)
+	}
+	defer conn.Close()
+	logrus.Info("feishu websocket connected")
+
+	w.mu.Lock()
+	w.conn = conn
+	w.serviceId = int32(serviceId)
+	w.config = config
+	w.fragments = make(map[string]*webSocketFragments)
+	w.mu.Unlock()
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// unblock ReadMessage when ctx is done
+		<-connCtx.Done()
+		conn.Close()
+	}()
+	go w.ping(connCtx)
+
+	busy := make(chan struct{}, w.handlers)
+	for {
+		msgType, data, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		if msgType != websocket.BinaryMessage {
+			continue
+		}
+		frame, err := unmarshalWebSocketFrame(data)
+		if err != nil {
+			logrus.WithError(err).Warn("drop invalid feishu websocket frame")
+			continue
+		}
+
+		switch frame.Method {
+		case webSocketFrameControl:
+			w.handleControl(frame)
+		case webSocketFrameData:
+			select {
+			case busy <- struct{}{}:
+			case <-connCtx.Done():
+				return true, connCtx.Err()
+			}
+			go func() {
+				defer func() { <-busy }()
+				w.handleData(connCtx, frame)
+			}()
+		}
+	}
+}
+
+func (w *WebSocketClient) pingInterval() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.config.PingInterval > 0 {
+		return time.Duration(w.config.PingInterval) * time.Second
+	}
+	return defaultWebSocketPingInterval
+}
+
+func (w *WebSocketClient) ping(ctx context.Context) {
+	for {
+		timer := time.NewTimer(w.pingInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		w.mu.Lock()
+		frame := &webSocketFrame{
+			Service: w.serviceId,
+			Method:  webSocketFrameControl,
+			Headers: []webSocketHeader{{Key: "type", Value: "ping"}},
+		}
+		w.mu.Unlock()
+		if err := w.write(frame); err != nil {
+			logrus.WithError(err).Warn("feishu websocket ping fail")
+			return
+		}
+	}
+}
+
+// The pong may carry the new client config
+func (w *WebSocketClient) handleControl(frame *webSocketFrame) {
+	if frame.Header("type") != "pong" || len(frame.Payload) == 0 {
+		return
+	}
+	var config webSocketClientConfig
+	if err := json.Unmarshal(frame.Payload, &config); err != nil {
+		return
+	}
+	w.mu.Lock()
+	w.config = config
+	w.mu.Unlock()
+}
+
+func (w *WebSocketClient) handleData(ctx context.Context, frame *webSocketFrame) {
+	payload := w.combine(frame)
+	if payload == nil {
+		return
+	}
+
+	start := time.Now()
+	code := http.StatusOK
+	var data []byte
+	var err error
+	switch frame.Header("type") {
+	case "event":
+		err = w.handleEvent(ctx, payload)
+	case "card":
+		data, err = w.handleCard(ctx, payload)
+	default:
+		return
+	}
+	if err != nil {
+		logrus.WithField("MessageID", frame.Header("message_id")).WithError(err).Error("handle feishu websocket message fail")
+		code = http.StatusInternalServerError
+	}
+
+	ack := map[string]any{"code": code, "headers": map[string]string{}, "data": data}
+	body, _ := json.Marshal(ack)
+	frame.Payload = body
+	frame.SetHeader("biz_rt", strconv.FormatInt(time.Since(start).Milliseconds(), 10))
+	if err := w.write(frame); err != nil {
+		logrus.WithError(err).Warn("ack feishu websocket message fail")
+	}
+}
+
+func (w *WebSocketClient) handleEvent(ctx context.Context, payload []byte) error {
+	event, err := parseEvent(payload)
+	if err != nil {
+		return err
+	}
+	return w.handle(ctx, event)
+}
+
+func (w *WebSocketClient) handleCard(ctx context.Context, payload []byte) ([]byte, error) {
+	if w.cardHandle == nil {
+		return nil, nil
+	}
+	action, _, schema, _, err := parseCardAction(payload)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.cardHandle(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cardActionResponseBody(resp, schema))
+}
+
+// A large message is split into several frames, return the whole payload once all of them arrive
+func (w *WebSocketClient) combine(frame *webSocketFrame) []byte {
+	sum, _ := strconv.Atoi(frame.Header("sum"))
+	if sum <= 1 {
+		return frame.Payload
+	}
+	seq, _ := strconv.Atoi(frame.Header("seq"))
+	if seq < 0 || seq >= sum {
+		return nil
+	}
+	messageId := frame.Header("message_id")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	for id, f := range w.fragments {
+		if now.Sub(f.createdAt) > webSocketFragmentTTL {
+			delete(w.fragments, id)
+		}
+	}
+
+	f, ok := w.fragments[messageId]
+	if !ok || len(f.parts) != sum {
+		f = &webSocketFragments{parts: make([][]byte, sum), createdAt: now}
+		w.fragments[messageId] = f
+	}
+	f.parts[seq] = frame.Payload
+
+	var payload []byte
+	for _, part := range f.parts {
+		if part == nil {
+			return nil
+		}
+		payload = append(payload, part...)
+	}
+	delete(w.fragments, messageId)
+	return payload
+}
+
+func (w *WebSocketClient) write(frame *webSocketFrame) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return errors.New("websocket is not connected")
+	}
+	return w.conn.WriteMessage(websocket.BinaryMessage, frame.Marshal())
+}
@@ -0,0 +1,208 @@
+package feishuapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The method of webSocketFrame
+const (
+	webSocketFrameControl int32 = 0
+	webSocketFrameData    int32 = 1
+)
+
+type webSocketHeader struct {
+	Key   string
+	Value string
+}
+
+// webSocketFrame is the protobuf message carried by every binary message of the long connection
+type webSocketFrame struct {
+	SeqId           uint64
+	LogId           uint64
+	Service         int32
+	Method          int32
+	Headers         []webSocketHeader
+	PayloadEncoding string
+	PayloadType     string
+	Payload         []byte
+	LogIdNew        string
+}
+
+func (f *webSocketFrame) Header(key string) string {
+	for _, h := range f.Headers {
+		if h.Key == key {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+func (f *webSocketFrame) SetHeader(key string, value string) {
+	for i, h := range f.Headers {
+		if h.Key == key {
+			f.Headers[i].Value = value
+			return
+		}
+	}
+	f.Headers = append(f.Headers, webSocketHeader{Key: key, Value: value})
+}
+
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+func (f *webSocketFrame) Marshal() []byte {
+	var b []byte
+	b = appendProtoVarint(b, 1, f.SeqId)
+	b = appendProtoVarint(b, 2, f.LogId)
+	b = appendProtoVarint(b, 3, uint64(int64(f.Service)))
+	b = appendProtoVarint(b, 4, uint64(int64(f.Method)))
+	for _, h := range f.Headers {
+		var header []byte
+		header = appendProtoBytes(header, 1, []byte(h.Key))
+		header = appendProtoBytes(header, 2, []byte(h.Value))
+		b = appendProtoBytes(b, 5, header)
+	}
+	if f.PayloadEncoding != "" {
+		b = appendProtoBytes(b, 6, []byte(f.PayloadEncoding))
+	}
+	if f.PayloadType != "" {
+		b = appendProtoBytes(b, 7, []byte(f.PayloadType))
+	}
+	if f.Payload != nil {
+		b = appendProtoBytes(b, 8, f.Payload)
+	}
+	if f.LogIdNew != "" {
+		b = appendProtoBytes(b, 9, []byte(f.LogIdNew))
+	}
+	return b
+}
+
+func unmarshalWebSocketFrame(data []byte) (*webSocketFrame, error) {
+	f := &webSocketFrame{}
+	err := walkProto(data, func(field int, varint uint64, bytes []byte) error {
+		switch field {
+		case 1:
+			f.SeqId = varint
+		case 2:
+			f.LogId = varint
+		case 3:
+			f.Service = int32(varint)
+		case 4:
+			f.Method = int32(varint)
+		case 5:
+			var h webSocketHeader
+			if err := walkProto(bytes, func(field int, varint uint64, bytes []byte) error {
+				switch field {
+				case 1:
+					h.Key = string(bytes)
+				case 2:
+					h.Value = string(bytes)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			f.Headers = append(f.Headers, h)
+		case 6:
+			f.PayloadEncoding = string(bytes)
+		case 7:
+			f.PayloadType = string(bytes)
+		case 8:
+			f.Payload = append([]byte(nil), bytes...)
+		case 9:
+			f.LogIdNew = string(bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decode websocket frame: %w", err)
+	}
+	return f, nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	b = appendUvarint(b, uint64(field)<<3|protoVarint)
+	return appendUvarint(b, v)
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = appendUvarint(b, uint64(field)<<3|protoBytes)
+	b = appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+var errProtoTruncated = errors.New("truncated protobuf message")
+
+func readUvarint(data []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		v |= uint64(data[i]&0x7f) << (7 * i)
+		if data[i] < 0x80 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, errProtoTruncated
+}
+
+// Call fn with every field of the message, the varint fields with varint and the length-delimited ones with bytes
+func walkProto(data []byte, fn func(field int, varint uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		key, n, err := readUvarint(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+		field := int(key >> 3)
+
+		switch key & 7 {
+		case protoVarint:
+			v, n, err := readUvarint(data)
+			if err != nil {
+				return err
+			}
+			data = data[n:]
+			if err := fn(field, v, nil); err != nil {
+				return err
+			}
+		case protoBytes:
+			l, n, err := readUvarint(data)
+			if err != nil {
+				return err
+			}
+			data = data[n:]
+			if uint64(len(data)) < l {
+				return errProtoTruncated
+			}
+			if err := fn(field, 0, data[:l]); err != nil {
+				return err
+			}
+			data = data[l:]
+		case protoFixed64:
+			if len(data) < 8 {
+				return errProtoTruncated
+			}
+			data = data[8:]
+		case protoFixed32:
+			if len(data) < 4 {
+				return errProtoTruncated
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&7)
+		}
+	}
+	return nil
+}
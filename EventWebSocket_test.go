@@ -0,0 +1,233 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketClient(t *testing.T) {
+	var mu sync.Mutex
+	var acks []map[string]any
+	pinged := make(chan struct{}, 1)
+	connections := 0
+
+	upgrader := websocket.Upgrader{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/callback/ws/endpoint":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["AppID"] != "cli_ws" || r.Header.Get("Authorization") != "" {
+				t.Errorf("unexpected endpoint request %v %v", body, r.Header)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{
+				"URL":          "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?device_id=d&service_id=7",
+				"ClientConfig": map[string]any{"ReconnectCount": -1, "ReconnectInterval": 1, "ReconnectNonce": 0, "PingInterval": 1},
+			}})
+		case "/ws":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			mu.Lock()
+			connections++
+			first := connections == 1
+			mu.Unlock()
+
+			event := `{"schema":"2.0","header":{"event_id":"e1","event_type":"im.message.receive_v1"},"event":{"message":{"message_id":"om_1","chat_id":"oc_1","message_type":"text","content":"{}"}}}`
+			if !first {
+				event = strings.Replace(event, "e1", "e2", 1)
+			}
+			// send the event in two parts
+			for i, part := range []string{event[:20], event[20:]} {
+				frame := &webSocketFrame{
+					SeqId:   uint64(i),
+					Service: 7,
+					Method:  webSocketFrameData,
+					Headers: []webSocketHeader{
+						{Key: "type", Value: "event"},
+						{Key: "message_id", Value: "m"},
+						{Key: "sum", Value: "2"},
+						{Key: "seq", Value: string(rune('0' + i))},
+					},
+					Payload: []byte(part),
+				}
+				conn.WriteMessage(websocket.BinaryMessage, frame.Marshal())
+			}
+
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				frame, err := unmarshalWebSocketFrame(data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if frame.Method == webSocketFrameControl && frame.Header("type") == "ping" && frame.Service == 7 {
+					select {
+					case pinged <- struct{}{}:
+					default:
+					}
+					continue
+				}
+				var ack map[string]any
+				json.Unmarshal(frame.Payload, &ack)
+				mu.Lock()
+				acks = append(acks, ack)
+				mu.Unlock()
+				if first {
+					// drop the connection, the client should reconnect
+					return
+				}
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var cli AppClient
+	cli.Conf.AppId = "cli_ws"
+	cli.Conf.AppSecret = "secret"
+	cli.Conf.BaseURL = server.URL
+
+	received := make(chan string, 2)
+	dispatcher := NewEventDispatcher().OnMessageReceive(func(ctx context.Context, event *MessageReceiveEvent) error {
+		received <- event.Header.EventId
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- cli.NewWebSocketClient(dispatcher.Dispatch).Start(ctx) }()
+
+	for _, want := range []string{"e1", "e2"} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("expected %s, got %s", want, got)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timeout waiting for %s", want)
+		}
+	}
+	select {
+	case <-pinged:
+	case <-ctx.Done():
+		t.Fatal("timeout waiting for ping")
+	}
+
+	// the ack is written after the handler returns
+	for {
+		mu.Lock()
+		n := len(acks)
+		mu.Unlock()
+		if n >= 2 || ctx.Err() != nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	cancel()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if len(acks) != 2 || acks[0]["code"] != float64(200) || acks[1]["code"] != float64(200) {
+		t.Fatalf("unexpected acks %v", acks)
+	}
+}
+
+func TestWebSocketClientMaxHandlers(t *testing.T) {
+	acked := make(chan struct{}, 3)
+	upgrader := websocket.Upgrader{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/callback/ws/endpoint":
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{
+				"URL":          "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?service_id=7",
+				"ClientConfig": map[string]any{"ReconnectCount": -1, "PingInterval": 60},
+			}})
+		case "/ws":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			for _, id := range []string{"e1", "e2", "e3"} {
+				frame := &webSocketFrame{
+					Service: 7,
+					Method:  webSocketFrameData,
+					Headers: []webSocketHeader{{Key: "type", Value: "event"}, {Key: "message_id", Value: id}},
+					Payload: []byte(`{"schema":"2.0","header":{"event_id":"` + id + `","event_type":"im.message.receive_v1"},"event":{}}`),
+				}
+				conn.WriteMessage(websocket.BinaryMessage, frame.Marshal())
+			}
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+				acked <- struct{}{}
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var cli AppClient
+	cli.Conf.AppId = "cli_ws_max_handlers"
+	cli.Conf.AppSecret = "secret"
+	cli.Conf.BaseURL = server.URL
+
+	var mu sync.Mutex
+	running, most := 0, 0
+	handle := func(ctx context.Context, event *Event) error {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond * 50)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- cli.NewWebSocketClient(handle).WithMaxHandlers(1).Start(ctx) }()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-acked:
+		case <-ctx.Done():
+			t.Fatalf("timeout waiting for ack %d", i)
+		}
+	}
+	cancel()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if most != 1 {
+		t.Fatalf("%d messages were handled at the same time, expected 1", most)
+	}
+}
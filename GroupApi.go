@@ -1,6 +1,10 @@
 package feishuapi
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
 
 type GroupInfo struct {
 	ChatId    string
@@ -19,18 +23,30 @@ func NewGroupInfo(data map[string]any) *GroupInfo {
 
 // Get All the chat group that the feishu robot is in
 func (c AppClient) GroupGetAllInfo() []GroupInfo {
-	l := c.GetAllPages("get", "open-apis/im/v1/chats", nil, nil, nil, 100)
-	if l == nil {
-		logrus.Warn("nil group info return")
+	all_groups, err := c.GroupGetAllInfoWithError()
+	if err != nil {
+		logrus.WithError(err).Warn("nil group info return")
 		return nil
 	}
+	return all_groups
+}
+
+func (c AppClient) GroupGetAllInfoWithError() ([]GroupInfo, error) {
+	return c.GroupGetAllInfoWithContext(context.Background())
+}
+
+func (c AppClient) GroupGetAllInfoWithContext(ctx context.Context) ([]GroupInfo, error) {
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/im/v1/chats", nil, nil, nil, 100)
+	if err != nil {
+		return nil, err
+	}
 
 	var all_groups []GroupInfo
 	for _, value := range l {
 		all_groups = append(all_groups, *NewGroupInfo(value.(map[string]any)))
 	}
 
-	return all_groups
+	return all_groups, nil
 }
 
 type GroupMember struct {
@@ -48,143 +64,187 @@ func NewGroupMember(data map[string]any) *GroupMember {
 
 // Get all the group members in a specific group
 func (c AppClient) GroupGetMembers(groupId string, userIdType UserIdType) []GroupMember {
-	body := make(map[string]string)
-	body["member_id_type"] = string(userIdType)
-
-	l := c.GetAllPages("get", "open-apis/im/v1/chats/"+groupId+"/members", nil, nil, body, 100)
-	if l == nil {
-		logrus.WithField("GroupID", groupId).Warn("nil group member info return")
+	all_members, err := c.GroupGetMembersWithError(groupId, userIdType)
+	if err != nil {
+		logrus.WithField("GroupID", groupId).WithError(err).Warn("nil group member info return")
 		return nil
 	}
+	return all_members
+}
+
+func (c AppClient) GroupGetMembersWithError(groupId string, userIdType UserIdType) ([]GroupMember, error) {
+	return c.GroupGetMembersWithContext(context.Background(), groupId, userIdType)
+}
+
+func (c AppClient) GroupGetMembersWithContext(ctx context.Context, groupId string, userIdType UserIdType) ([]GroupMember, error) {
+	query := make(map[string]any)
+	query["member_id_type"] = string(userIdType)
+
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/im/v1/chats/"+groupId+"/members", query, nil, nil, 100)
+	if err != nil {
+		return nil, err
+	}
 
 	var all_members []GroupMember
 	for _, value := range l {
 		all_members = append(all_members, *NewGroupMember(value.(map[string]any)))
 	}
 
-	return all_members
+	return all_members, nil
 }
 
 // CreateGroup Create a new group
 func (c AppClient) GroupCreate(groupName string, userIdType UserIdType, ownerId string) *GroupInfo {
+	info, err := c.GroupCreateWithError(groupName, userIdType, ownerId)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"GroupName": groupName,
+			"OwnerID":   ownerId,
+			"error":     err,
+		}).Error("create group fail")
+		return nil
+	}
+	return info
+}
+
+func (c AppClient) GroupCreateWithError(groupName string, userIdType UserIdType, ownerId string) (*GroupInfo, error) {
+	return c.GroupCreateWithContext(context.Background(), groupName, userIdType, ownerId)
+}
+
+func (c AppClient) GroupCreateWithContext(ctx context.Context, groupName string, userIdType UserIdType, ownerId string) (*GroupInfo, error) {
 	query := make(map[string]any)
 	query["user_id_type"] = string(userIdType)
 	body := make(map[string]string)
 	body["name"] = groupName
 	body["owner_id"] = ownerId
 
-	info := c.Request("post", "open-apis/im/v1/chats", query, nil, body)
-
-	if info == nil {
-		logrus.WithFields(logrus.Fields{
-			"GroupName": groupName,
-			"OwnerID":   ownerId,
-		}).Error("create group fail")
-		return nil
+	info, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/chats", query, nil, body)
+	if err != nil {
+		return nil, err
 	}
 
-	return NewGroupInfo(info)
+	return NewGroupInfo(info), nil
 }
 
 // GetGroupInfo Get a group information
 func (c AppClient) GroupGetInfo(chatId string) *GroupInfo {
-	info := c.Request("get", "open-apis/im/v1/chats/"+chatId+"?user_id_type=open_id", nil, nil, nil)
-
-	if info == nil {
-		logrus.WithField("ChatID", chatId).Warn("nil group info return")
+	info, err := c.GroupGetInfoWithError(chatId)
+	if err != nil {
+		logrus.WithField("ChatID", chatId).WithError(err).Warn("nil group info return")
 		return nil
 	}
+	return info
+}
+
+func (c AppClient) GroupGetInfoWithError(chatId string) (*GroupInfo, error) {
+	return c.GroupGetInfoWithContext(context.Background(), chatId)
+}
+
+func (c AppClient) GroupGetInfoWithContext(ctx context.Context, chatId string) (*GroupInfo, error) {
+	query := make(map[string]any)
+	query["user_id_type"] = "open_id"
+
+	info, err := c.RequestWithContext(ctx, "get", "open-apis/im/v1/chats/"+chatId, query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	info["chat_id"] = chatId
 	info["tenant_key"] = ""
-	return NewGroupInfo(info)
+	return NewGroupInfo(info), nil
 }
 
 // AddMembers
 // app_id to add bot
 func (c AppClient) GroupAddMembers(chatId string, memberIdType UserIdType, succeedType string, idList []string) bool {
-	query := make(map[string]any)
-	query["member_id_type"] = string(memberIdType)
-	query["succeed_type"] = succeedType
-
-	body := make(map[string][]string)
-
-	var result bool = true
-	var idlist []string
-
-	for len(idList) > 50 {
-		idlist = idList[0:50]
-		body["id_list"] = idlist
-		resp := c.Request("post", "open-apis/im/v1/chats/"+chatId+"/members", query, nil, body)
-		idList = append(idList[:0], idList[50:]...)
-		if resp == nil {
-			logrus.WithFields(logrus.Fields{
-				"ChatID": chatId,
-				"IdList": idList,
-			}).Error("add member fail")
-			result = false
-		}
-	}
-	body["id_list"] = idList
-	resp := c.Request("post", "open-apis/im/v1/chats/"+chatId+"/members", query, nil, body)
-	if resp == nil {
+	if err := c.GroupAddMembersWithError(chatId, memberIdType, succeedType, idList); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"ChatID": chatId,
 			"IdList": idList,
+			"error":  err,
 		}).Error("add member fail")
-		result = false
+		return false
 	}
-	return result
+	return true
 }
 
-// DeleteMembers
-// app_id to delete bot
-func (c AppClient) GroupDeleteMembers(chatId string, memberIdType UserIdType, idList []string) bool {
+// Same as GroupAddMembers, members are added 50 at a time and the errors of every batch are joined together
+func (c AppClient) GroupAddMembersWithError(chatId string, memberIdType UserIdType, succeedType string, idList []string) error {
+	return c.GroupAddMembersWithContext(context.Background(), chatId, memberIdType, succeedType, idList)
+}
+
+func (c AppClient) GroupAddMembersWithContext(ctx context.Context, chatId string, memberIdType UserIdType, succeedType string, idList []string) error {
 	query := make(map[string]any)
 	query["member_id_type"] = string(memberIdType)
+	query["succeed_type"] = succeedType
 
-	body := make(map[string][]string)
-
-	var result bool = true
-	var idlist []string
-
-	for len(idList) > 50 {
-		idlist = idList[0:50]
-		body["id_list"] = idlist
-		resp := c.Request("delete", "open-apis/im/v1/chats/"+chatId+"/members", query, nil, body)
-		idList = append(idList[:0], idList[50:]...)
-		if resp == nil {
-			logrus.WithFields(logrus.Fields{
-				"ChatID": chatId,
-				"IdList": idList,
-			}).Error("delete member fail")
-			result = false
-		}
-	}
-	body["id_list"] = idList
-	resp := c.Request("delete", "open-apis/im/v1/chats/"+chatId+"/members", query, nil, body)
-	if resp == nil {
+	return c.groupMembersInBatches(ctx, "post", chatId, query, idList)
+}
+
+// DeleteMembers
+// app_id to delete bot
+func (c AppClient) GroupDeleteMembers(chatId string, memberIdType UserIdType, idList []string) bool {
+	if err := c.GroupDeleteMembersWithError(chatId, memberIdType, idList); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"ChatID": chatId,
 			"IdList": idList,
+			"error":  err,
 		}).Error("delete member fail")
-		result = false
+		return false
 	}
-	return result
+	return true
 }
 
-func (c AppClient) GroupChangeOwner(chatId string, memberIdType UserIdType, ownerId string) {
+// Same as GroupDeleteMembers, members are deleted 50 at a time and the errors of every batch are joined together
+func (c AppClient) GroupDeleteMembersWithError(chatId string, memberIdType UserIdType, idList []string) error {
+	return c.GroupDeleteMembersWithContext(context.Background(), chatId, memberIdType, idList)
+}
+
+func (c AppClient) GroupDeleteMembersWithContext(ctx context.Context, chatId string, memberIdType UserIdType, idList []string) error {
 	query := make(map[string]any)
-	query["user_id_type"] = string(memberIdType)
+	query["member_id_type"] = string(memberIdType)
 
-	body := make(map[string]string)
-	body["owner_id"] = ownerId
+	return c.groupMembersInBatches(ctx, "delete", chatId, query, idList)
+}
+
+func (c AppClient) groupMembersInBatches(ctx context.Context, method string, chatId string, query map[string]any, idList []string) error {
+	var errs []error
+	for start := 0; start == 0 || start < len(idList); start += 50 {
+		end := start + 50
+		if end > len(idList) {
+			end = len(idList)
+		}
 
-	resp := c.Request("put", "open-apis/im/v1/chats/"+chatId, query, nil, body)
-	if resp == nil {
+		body := make(map[string][]string)
+		body["id_list"] = idList[start:end]
+		if _, err := c.RequestWithContext(ctx, method, "open-apis/im/v1/chats/"+chatId+"/members", query, nil, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
+
+func (c AppClient) GroupChangeOwner(chatId string, memberIdType UserIdType, ownerId string) {
+	if err := c.GroupChangeOwnerWithError(chatId, memberIdType, ownerId); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"ChatID":     chatId,
 			"NewOwnerId": ownerId,
+			"error":      err,
 		}).Warn("change group owner fail")
 	}
 }
+
+func (c AppClient) GroupChangeOwnerWithError(chatId string, memberIdType UserIdType, ownerId string) error {
+	return c.GroupChangeOwnerWithContext(context.Background(), chatId, memberIdType, ownerId)
+}
+
+func (c AppClient) GroupChangeOwnerWithContext(ctx context.Context, chatId string, memberIdType UserIdType, ownerId string) error {
+	query := make(map[string]any)
+	query["user_id_type"] = string(memberIdType)
+
+	body := make(map[string]string)
+	body["owner_id"] = ownerId
+
+	_, err := c.RequestWithContext(ctx, "put", "open-apis/im/v1/chats/"+chatId, query, nil, body)
+	return err
+}
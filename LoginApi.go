@@ -0,0 +1,257 @@
+package feishuapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type LoginUserInfo struct {
+	Name      string
+	EnName    string
+	AvatarUrl string
+	OpenId    string
+	UnionId   string
+	UserId    string
+	Email     string
+	Mobile    string
+	TenantKey string
+}
+
+// Create a new LoginUserInfo
+func NewLoginUserInfo(data map[string]any) *LoginUserInfo {
+	return &LoginUserInfo{
+		Name:      getInMap(data, "name", "").(string),
+		EnName:    getInMap(data, "en_name", "").(string),
+		AvatarUrl: getInMap(data, "avatar_url", "").(string),
+		OpenId:    getInMap(data, "open_id", "").(string),
+		UnionId:   getInMap(data, "union_id", "").(string),
+		UserId:    getInMap(data, "user_id", "").(string),
+		Email:     getInMap(data, "email", "").(string),
+		Mobile:    getInMap(data, "mobile", "").(string),
+		TenantKey: getInMap(data, "tenant_key", "").(string),
+	}
+}
+
+// Get the info of the user who owns the user_access_token
+func (c AppClient) GetLoginUserInfo(user_access_token string) *LoginUserInfo {
+	info, err := c.GetLoginUserInfoWithError(user_access_token)
+	if err != nil {
+		logrus.WithError(err).Error("nil login user info return")
+		return nil
+	}
+	return info
+}
+
+func (c AppClient) GetLoginUserInfoWithError(user_access_token string) (*LoginUserInfo, error) {
+	return c.GetLoginUserInfoWithContext(context.Background(), StaticUserToken(user_access_token))
+}
+
+func (c AppClient) GetLoginUserInfoWithContext(ctx context.Context, userToken UserToken) (*LoginUserInfo, error) {
+	headers, err := userAuthHeaders(ctx, userToken)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.RequestWithContext(ctx, "get", "open-apis/authen/v1/user_info", nil, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewLoginUserInfo(resp), nil
+}
+
+// Build the url of the Feishu login page, which redirects the browser to redirectURL with code and state
+func (c AppClient) AuthorizeURL(redirectURL string, state string) string {
+	query := url.Values{}
+	query.Set("app_id", c.Conf.AppId)
+	query.Set("redirect_uri", redirectURL)
+	if state != "" {
+		query.Set("state", state)
+	}
+	return c.url("open-apis/authen/v1/index") + "?" + query.Encode()
+}
+
+// LoginResult is passed to the session hook after the user has logged in
+type LoginResult struct {
+	Token *UserAccessToken
+	User  *LoginUserInfo
+	// the local path which is given to the login handler by the "redirect" query
+	RedirectTo string
+}
+
+// LoginConfig configures LoginHandler and LoginCallbackHandler
+type LoginConfig struct {
+	// the url of LoginCallbackHandler, it must be registered as a redirect url of the app
+	RedirectURL string
+	// the key which signs the state, Conf.AppSecret is used if it is empty
+	StateSecret []byte
+	// how long the login page may stay open, 10 minutes by default
+	StateTTL time.Duration
+	// called after the user has logged in, it should set up the session and respond to the browser.
+	// The browser is redirected to result.RedirectTo if it is nil
+	OnLogin func(w http.ResponseWriter, r *http.Request, result *LoginResult) error
+	// called when the login fails, respond with http.Error if it is nil
+	OnError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+const (
+	defaultLoginStateTTL = time.Minute * 10
+	loginStateCookie     = "feishuapi_login_state"
+)
+
+var ErrInvalidLoginState = errors.New("invalid login state")
+
+// LoginHandler redirects the browser to the Feishu login page with a signed state.
+// The optional "redirect" query is a local path which the browser goes to after login
+func (c AppClient) LoginHandler(conf LoginConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce, err := loginNonce()
+		if err != nil {
+			conf.fail(w, r, err)
+			return
+		}
+
+		redirectTo := r.URL.Query().Get("redirect")
+		if !isLocalPath(redirectTo) {
+			redirectTo = "/"
+		}
+		state := conf.signState(c, nonce, redirectTo, time.Now())
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     loginStateCookie,
+			Value:    nonce,
+			Path:     "/",
+			MaxAge:   int(conf.stateTTL() / time.Second),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, c.AuthorizeURL(conf.RedirectURL, state), http.StatusFound)
+	})
+}
+
+// LoginCallbackHandler validates the state, exchanges the code for a UserAccessToken,
+// gets the info of the user and hands them to conf.OnLogin
+func (c AppClient) LoginCallbackHandler(conf LoginConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		cookie, err := r.Cookie(loginStateCookie)
+		if err != nil {
+			conf.fail(w, r, ErrInvalidLoginState)
+			return
+		}
+		redirectTo, err := conf.verifyState(c, query.Get("state"), cookie.Value, time.Now())
+		if err != nil {
+			conf.fail(w, r, err)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: loginStateCookie, Path: "/", MaxAge: -1})
+
+		code := query.Get("code")
+		if code == "" {
+			conf.fail(w, r, errors.New("code is missing in login callback"))
+			return
+		}
+
+		token, err := c.GetUserAccessTokenWithContext(r.Context(), code)
+		if err != nil {
+			conf.fail(w, r, err)
+			return
+		}
+		user, err := c.GetLoginUserInfoWithContext(r.Context(), StaticUserToken(token.Access_token))
+		if err != nil {
+			conf.fail(w, r, err)
+			return
+		}
+
+		result := &LoginResult{Token: token, User: user, RedirectTo: redirectTo}
+		if conf.OnLogin == nil {
+			http.Redirect(w, r, redirectTo, http.StatusFound)
+			return
+		}
+		if err := conf.OnLogin(w, r, result); err != nil {
+			conf.fail(w, r, err)
+		}
+	})
+}
+
+func (conf LoginConfig) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if conf.OnError != nil {
+		conf.OnError(w, r, err)
+		return
+	}
+	logrus.WithError(err).Warn("feishu login fail")
+	if errors.Is(err, ErrInvalidLoginState) {
+		http.Error(w, "invalid login state", http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "login fail", http.StatusInternalServerError)
+}
+
+func (conf LoginConfig) stateTTL() time.Duration {
+	if conf.StateTTL > 0 {
+		return conf.StateTTL
+	}
+	return defaultLoginStateTTL
+}
+
+func (conf LoginConfig) stateMAC(c AppClient, payload string) string {
+	secret := conf.StateSecret
+	if len(secret) == 0 {
+		secret = []byte(c.Conf.AppSecret)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// state is base64(nonce "|" issued unix time "|" redirect path) "." base64(hmac)
+func (conf LoginConfig) signState(c AppClient, nonce string, redirectTo string, now time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(nonce + "|" + strconv.FormatInt(now.Unix(), 10) + "|" + redirectTo))
+	return payload + "." + conf.stateMAC(c, payload)
+}
+
+// Check the signature, age and the nonce in the cookie of the state, return the redirect path in it
+func (conf LoginConfig) verifyState(c AppClient, state string, nonce string, now time.Time) (string, error) {
+	payload, mac, ok := strings.Cut(state, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(conf.stateMAC(c, payload))) {
+		return "", ErrInvalidLoginState
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidLoginState
+	}
+
+	parts := strings.SplitN(string(data), "|", 3)
+	if len(parts) != 3 || nonce == "" || !hmac.Equal([]byte(parts[0]), []byte(nonce)) {
+		return "", ErrInvalidLoginState
+	}
+	issuedAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Sub(time.Unix(issuedAt, 0)) > conf.stateTTL() {
+		return "", ErrInvalidLoginState
+	}
+	return parts[2], nil
+}
+
+func loginNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Only redirect to the paths of this site after login
+func isLocalPath(p string) bool {
+	return strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "//") && !strings.HasPrefix(p, "/\\")
+}
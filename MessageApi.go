@@ -1,7 +1,11 @@
 package feishuapi
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -21,57 +25,427 @@ type MsgContentType string
 const (
 	Text        MsgContentType = "text"
 	Interactive MsgContentType = "interactive"
+	// the msg is the JSON of a MessagePost
+	Post MsgContentType = "post"
+	// the msg is the image_key got by ImageUpload
+	Image MsgContentType = "image"
+	// the msg of the types below is the file_key got by FileUpload,
+	// or the whole content in JSON, e.g. {"file_key": "...", "image_key": "..."} for a Media message with a cover
+	File    MsgContentType = "file"
+	Audio   MsgContentType = "audio"
+	Media   MsgContentType = "media"
+	Sticker MsgContentType = "sticker"
 )
 
+type MessageSender struct {
+	Id string
+	// "open_id" for a user, "app_id" for a bot
+	IdType     string
+	SenderType string
+	TenantKey  string
+}
+
+type Message struct {
+	MessageId string
+	RootId    string
+	ParentId  string
+	ThreadId  string
+	MsgType   MsgContentType
+	ChatId    string
+	Sender    MessageSender
+	// the content in JSON, its format depends on MsgType
+	Content string
+	// the parsed Content, which is *TextMessageContent, *PostMessageContent, *ImageMessageContent,
+	// *FileMessageContent or *CardMessageContent, nil for the other types
+	ParsedContent any
+	Mentions      []MessageMention
+	CreateTime    time.Time
+	UpdateTime    time.Time
+	Deleted       bool
+	Updated       bool
+	// the merge forward message which it belongs to
+	UpperMessageId string
+	// the messages in a merge forward message, only set by MessageGet
+	Children []Message
+}
+
+// Create a new Message
+func NewMessage(data map[string]any) *Message {
+	message := &Message{
+		MessageId:      getInMap(data, "message_id", "").(string),
+		RootId:         getInMap(data, "root_id", "").(string),
+		ParentId:       getInMap(data, "parent_id", "").(string),
+		ThreadId:       getInMap(data, "thread_id", "").(string),
+		MsgType:        MsgContentType(getInMap(data, "msg_type", "").(string)),
+		ChatId:         getInMap(data, "chat_id", "").(string),
+		CreateTime:     millisTime(getInMap(data, "create_time", "").(string)),
+		UpdateTime:     millisTime(getInMap(data, "update_time", "").(string)),
+		Deleted:        getInMap(data, "deleted", false).(bool),
+		Updated:        getInMap(data, "updated", false).(bool),
+		UpperMessageId: getInMap(data, "upper_message_id", "").(string),
+	}
+	if sender, ok := data["sender"].(map[string]any); ok {
+		message.Sender = MessageSender{
+			Id:         getInMap(sender, "id", "").(string),
+			IdType:     getInMap(sender, "id_type", "").(string),
+			SenderType: getInMap(sender, "sender_type", "").(string),
+			TenantKey:  getInMap(sender, "tenant_key", "").(string),
+		}
+	}
+	if body, ok := data["body"].(map[string]any); ok {
+		message.Content = getInMap(body, "content", "").(string)
+	}
+	message.ParsedContent = parseMessageContent(message.MsgType, message.Content)
+	mentions, _ := data["mentions"].([]any)
+	for _, v := range mentions {
+		mention, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		id := EventUserId{}
+		switch getInMap(mention, "id_type", "").(string) {
+		case "open_id":
+			id.OpenId = getInMap(mention, "id", "").(string)
+		case "union_id":
+			id.UnionId = getInMap(mention, "id", "").(string)
+		case "user_id":
+			id.UserId = getInMap(mention, "id", "").(string)
+		}
+		message.Mentions = append(message.Mentions, MessageMention{
+			Key:       getInMap(mention, "key", "").(string),
+			Id:        id,
+			Name:      getInMap(mention, "name", "").(string),
+			TenantKey: getInMap(mention, "tenant_key", "").(string),
+		})
+	}
+	return message
+}
+
 // Send a message to a person / chat group, return whether if it had been send successfully
 func (c AppClient) MessageSend(receiveIdType MsgReceiverType, receiveId string, msgType MsgContentType, msg string) (string, bool) {
+	messageId, err := c.MessageSendWithError(receiveIdType, receiveId, msgType, msg)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ReceiveID": receiveId,
+			"Msg":       msg,
+			"error":     err,
+		}).Error("message send error")
+		return "", false
+	}
+	return messageId, true
+}
+
+// Send a message to a person / chat group, return the message id
+func (c AppClient) MessageSendWithError(receiveIdType MsgReceiverType, receiveId string, msgType MsgContentType, msg string) (string, error) {
+	return c.MessageSendWithContext(context.Background(), receiveIdType, receiveId, msgType, msg)
+}
+
+func (c AppClient) MessageSendWithContext(ctx context.Context, receiveIdType MsgReceiverType, receiveId string, msgType MsgContentType, msg string) (string, error) {
 	query := make(map[string]any)
 	query["receive_id_type"] = string(receiveIdType)
 
-	content := ""
+	content, err := messageContent(msgType, msg)
+	if err != nil {
+		return "", err
+	}
+	body := make(map[string]string)
+	body["receive_id"] = receiveId
+	body["content"] = content
+	body["msg_type"] = string(msgType)
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/messages", query, nil, body)
+	if err != nil {
+		return "", err
+	}
 
+	messageId, _ := resp["message_id"].(string)
+	return messageId, nil
+}
+
+// Build the content of a message from msg, which is the text of a Text message, or the JSON of the others
+func messageContent(msgType MsgContentType, msg string) (string, error) {
 	switch msgType {
 	case Text:
 		contmap := make(map[string]string)
 		contmap["text"] = msg
 		bytecont, err := json.Marshal(contmap)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"receive_id_type": string(receiveIdType),
-				"receive_id":      receiveId,
-				"msg_type":        string(msgType),
-				"msg":             msg,
-			}).Error("marshal text to json fail")
-			return "", false
+			return "", fmt.Errorf("marshal text to json fail: %w", err)
 		}
-		content = string(bytecont)
-	case Interactive:
-		content = msg
-	default:
-		logrus.WithField("msgType", msgType).Error("message type unsupported")
-		return "", false
+		return string(bytecont), nil
+	case Interactive, Post:
+		return msg, nil
+	case Image:
+		return keyContent("image_key", msg)
+	case File, Audio, Media, Sticker:
+		return keyContent("file_key", msg)
 	}
+	return "", fmt.Errorf("message type %s unsupported", msgType)
+}
+
+// Wrap the key into the content, the content in JSON is kept as it is
+func keyContent(name string, msg string) (string, error) {
+	if strings.HasPrefix(strings.TrimSpace(msg), "{") {
+		return msg, nil
+	}
+	data, err := json.Marshal(map[string]string{name: msg})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c AppClient) MessageUpdate(mid string, content string) {
+	if err := c.MessageUpdateWithError(mid, content); err != nil {
+		logrus.WithField("MessageID", mid).WithError(err).Error("message update error")
+	}
+}
+
+func (c AppClient) MessageUpdateWithError(mid string, content string) error {
+	return c.MessageUpdateWithContext(context.Background(), mid, content)
+}
+
+func (c AppClient) MessageUpdateWithContext(ctx context.Context, mid string, content string) error {
 	body := make(map[string]string)
-	body["receive_id"] = receiveId
+	body["content"] = content
+
+	_, err := c.RequestWithContext(ctx, "patch", "open-apis/im/v1/messages/"+mid, nil, nil, body)
+	return err
+}
+
+// Reply to a message, the reply is shown in the thread of the message if replyInThread is true
+func (c AppClient) MessageReply(messageId string, msgType MsgContentType, msg string, replyInThread bool) *Message {
+	result, err := c.MessageReplyWithError(messageId, msgType, msg, replyInThread)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message reply error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageReplyWithError(messageId string, msgType MsgContentType, msg string, replyInThread bool) (*Message, error) {
+	return c.MessageReplyWithContext(context.Background(), messageId, msgType, msg, replyInThread)
+}
+
+func (c AppClient) MessageReplyWithContext(ctx context.Context, messageId string, msgType MsgContentType, msg string, replyInThread bool) (*Message, error) {
+	content, err := messageContent(msgType, msg)
+	if err != nil {
+		return nil, err
+	}
+	body := make(map[string]any)
 	body["content"] = content
 	body["msg_type"] = string(msgType)
+	body["reply_in_thread"] = replyInThread
 
-	resp := c.Request("post", "open-apis/im/v1/messages", query, nil, body)
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/messages/"+messageId+"/reply", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	return NewMessage(resp), nil
+}
 
-	if resp == nil {
-		logrus.WithFields(logrus.Fields{
-			"ReceiveID": receiveId,
-			"Msg":       msg,
-		}).Error("message send error")
-		return "", false
+// Forward a message to a person / chat group, return the new message
+func (c AppClient) MessageForward(messageId string, receiveIdType MsgReceiverType, receiveId string) *Message {
+	result, err := c.MessageForwardWithError(messageId, receiveIdType, receiveId)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message forward error")
+		return nil
 	}
+	return result
+}
 
-	return resp["message_id"].(string), true
+func (c AppClient) MessageForwardWithError(messageId string, receiveIdType MsgReceiverType, receiveId string) (*Message, error) {
+	return c.MessageForwardWithContext(context.Background(), messageId, receiveIdType, receiveId)
 }
 
-func (c AppClient) MessageUpdate(mid string, content string) {
+func (c AppClient) MessageForwardWithContext(ctx context.Context, messageId string, receiveIdType MsgReceiverType, receiveId string) (*Message, error) {
+	query := make(map[string]any)
+	query["receive_id_type"] = string(receiveIdType)
 	body := make(map[string]string)
-	body["content"] = content
+	body["receive_id"] = receiveId
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/messages/"+messageId+"/forward", query, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	return NewMessage(resp), nil
+}
+
+type MergeForwardResult struct {
+	Message *Message
+	// the messages which can't be forwarded, e.g. recalled ones
+	InvalidMessageIds []string
+}
+
+// Forward the messages of one chat as a single merge forward message
+func (c AppClient) MessageMergeForward(messageIds []string, receiveIdType MsgReceiverType, receiveId string) *MergeForwardResult {
+	result, err := c.MessageMergeForwardWithError(messageIds, receiveIdType, receiveId)
+	if err != nil {
+		logrus.WithField("ReceiveID", receiveId).WithError(err).Error("message merge forward error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageMergeForwardWithError(messageIds []string, receiveIdType MsgReceiverType, receiveId string) (*MergeForwardResult, error) {
+	return c.MessageMergeForwardWithContext(context.Background(), messageIds, receiveIdType, receiveId)
+}
+
+func (c AppClient) MessageMergeForwardWithContext(ctx context.Context, messageIds []string, receiveIdType MsgReceiverType, receiveId string) (*MergeForwardResult, error) {
+	query := make(map[string]any)
+	query["receive_id_type"] = string(receiveIdType)
+	body := make(map[string]any)
+	body["receive_id"] = receiveId
+	body["message_id_list"] = messageIds
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/messages/merge_forward", query, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MergeForwardResult{}
+	if message, ok := resp["message"].(map[string]any); ok {
+		result.Message = NewMessage(message)
+	}
+	result.InvalidMessageIds = getStringsInMap(resp, "invalid_message_id_list")
+	return result, nil
+}
+
+// Recall a message sent by the bot
+func (c AppClient) MessageRecall(messageId string) {
+	if err := c.MessageRecallWithError(messageId); err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message recall error")
+	}
+}
+
+func (c AppClient) MessageRecallWithError(messageId string) error {
+	return c.MessageRecallWithContext(context.Background(), messageId)
+}
 
-	c.Request("patch", "open-apis/im/v1/messages/"+mid, nil, nil, body)
+func (c AppClient) MessageRecallWithContext(ctx context.Context, messageId string) error {
+	_, err := c.RequestWithContext(ctx, "delete", "open-apis/im/v1/messages/"+messageId, nil, nil, nil)
+	return err
+}
+
+// Get a message, the messages in it are put in Children if it is a merge forward message
+func (c AppClient) MessageGet(messageId string) *Message {
+	result, err := c.MessageGetWithError(messageId)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message get error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageGetWithError(messageId string) (*Message, error) {
+	return c.MessageGetWithContext(context.Background(), messageId)
+}
+
+func (c AppClient) MessageGetWithContext(ctx context.Context, messageId string) (*Message, error) {
+	resp, err := c.RequestWithContext(ctx, "get", "open-apis/im/v1/messages/"+messageId, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _ := resp["items"].([]any)
+	var message *Message
+	var children []Message
+	for _, v := range items {
+		item, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		m := NewMessage(item)
+		if m.MessageId == messageId && message == nil {
+			message = m
+		} else {
+			children = append(children, *m)
+		}
+	}
+	if message == nil {
+		return nil, fmt.Errorf("message %s is missing in response", messageId)
+	}
+	message.Children = children
+	return message, nil
+}
+
+type MessageReadUser struct {
+	UserId    string
+	ReadTime  time.Time
+	TenantKey string
+}
+
+// List the users who have read a message sent by the bot in the last 7 days
+func (c AppClient) MessageReadUsers(messageId string, userIdType UserIdType) []MessageReadUser {
+	result, err := c.MessageReadUsersWithError(messageId, userIdType)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message read users error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageReadUsersWithError(messageId string, userIdType UserIdType) ([]MessageReadUser, error) {
+	return c.MessageReadUsersWithContext(context.Background(), messageId, userIdType)
+}
+
+func (c AppClient) MessageReadUsersWithContext(ctx context.Context, messageId string, userIdType UserIdType) ([]MessageReadUser, error) {
+	query := make(map[string]any)
+	query["user_id_type"] = string(userIdType)
+
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/im/v1/messages/"+messageId+"/read_users", query, nil, nil, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]MessageReadUser, 0, len(l))
+	for _, value := range l {
+		data, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		users = append(users, MessageReadUser{
+			UserId:    getInMap(data, "user_id", "").(string),
+			ReadTime:  millisTime(getInMap(data, "timestamp", "").(string)),
+			TenantKey: getInMap(data, "tenant_key", "").(string),
+		})
+	}
+	return users, nil
+}
+
+// List the members of a chat group who haven't read a message sent by the bot to the group
+func (c AppClient) MessageUnreadMembers(messageId string, chatId string, userIdType UserIdType) []GroupMember {
+	result, err := c.MessageUnreadMembersWithError(messageId, chatId, userIdType)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message unread members error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageUnreadMembersWithError(messageId string, chatId string, userIdType UserIdType) ([]GroupMember, error) {
+	return c.MessageUnreadMembersWithContext(context.Background(), messageId, chatId, userIdType)
+}
+
+func (c AppClient) MessageUnreadMembersWithContext(ctx context.Context, messageId string, chatId string, userIdType UserIdType) ([]GroupMember, error) {
+	readUsers, err := c.MessageReadUsersWithContext(ctx, messageId, userIdType)
+	if err != nil {
+		return nil, err
+	}
+	members, err := c.GroupGetMembersWithContext(ctx, chatId, userIdType)
+	if err != nil {
+		return nil, err
+	}
+
+	read := make(map[string]bool, len(readUsers))
+	for _, user := range readUsers {
+		read[user.UserId] = true
+	}
+	unread := []GroupMember{}
+	for _, member := range members {
+		if !read[member.MemberId] {
+			unread = append(unread, member)
+		}
+	}
+	return unread, nil
 }
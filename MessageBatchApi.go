@@ -0,0 +1,159 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The receivers of a batch message, the users in the departments and their sub departments are included
+type BatchMessageTarget struct {
+	DepartmentIds []string
+	OpenIds       []string
+	UserIds       []string
+	UnionIds      []string
+}
+
+type BatchMessage struct {
+	// the id of the batch, which is used to track and recall it
+	MessageId string
+	// the receivers which the message can't be sent to
+	InvalidDepartmentIds []string
+	InvalidOpenIds       []string
+	InvalidUserIds       []string
+	InvalidUnionIds      []string
+}
+
+// Send a message to lots of users at once, msg is the same as MessageSend.
+// Only Text, Image, Post and Interactive messages can be sent in batch
+func (c AppClient) MessageBatchSend(target BatchMessageTarget, msgType MsgContentType, msg string) *BatchMessage {
+	result, err := c.MessageBatchSendWithError(target, msgType, msg)
+	if err != nil {
+		logrus.WithError(err).Error("message batch send error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageBatchSendWithError(target BatchMessageTarget, msgType MsgContentType, msg string) (*BatchMessage, error) {
+	return c.MessageBatchSendWithContext(context.Background(), target, msgType, msg)
+}
+
+func (c AppClient) MessageBatchSendWithContext(ctx context.Context, target BatchMessageTarget, msgType MsgContentType, msg string) (*BatchMessage, error) {
+	if len(target.DepartmentIds)+len(target.OpenIds)+len(target.UserIds)+len(target.UnionIds) == 0 {
+		return nil, errors.New("batch message has no receiver")
+	}
+
+	content, err := messageContent(msgType, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid([]byte(content)) {
+		return nil, fmt.Errorf("content of %s message is not valid JSON", msgType)
+	}
+
+	body := make(map[string]any)
+	body["msg_type"] = string(msgType)
+	// the content is an object instead of a string in the batch api
+	switch msgType {
+	case Text, Image:
+		body["content"] = json.RawMessage(content)
+	case Post:
+		body["content"] = map[string]any{"post": json.RawMessage(content)}
+	case Interactive:
+		body["card"] = json.RawMessage(content)
+	default:
+		return nil, fmt.Errorf("message type %s can't be sent in batch", msgType)
+	}
+	if len(target.DepartmentIds) != 0 {
+		body["department_ids"] = target.DepartmentIds
+	}
+	if len(target.OpenIds) != 0 {
+		body["open_ids"] = target.OpenIds
+	}
+	if len(target.UserIds) != 0 {
+		body["user_ids"] = target.UserIds
+	}
+	if len(target.UnionIds) != 0 {
+		body["union_ids"] = target.UnionIds
+	}
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/message/v4/batch_send", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	messageId, ok := resp["message_id"].(string)
+	if !ok {
+		return nil, errors.New("message_id is missing in response")
+	}
+	return &BatchMessage{
+		MessageId:            messageId,
+		InvalidDepartmentIds: getStringsInMap(resp, "invalid_department_ids"),
+		InvalidOpenIds:       getStringsInMap(resp, "invalid_open_ids"),
+		InvalidUserIds:       getStringsInMap(resp, "invalid_user_ids"),
+		InvalidUnionIds:      getStringsInMap(resp, "invalid_union_ids"),
+	}, nil
+}
+
+type BatchMessageProgress struct {
+	// the number of users which the message is going to be sent to
+	ValidUserCount   int
+	SuccessUserCount int
+	ReadUserCount    int
+	Recalled         bool
+	// the number of messages which have been recalled
+	RecallCount int
+}
+
+// Get the sending, reading and recalling progress of a batch message
+func (c AppClient) MessageBatchProgress(batchMessageId string) *BatchMessageProgress {
+	result, err := c.MessageBatchProgressWithError(batchMessageId)
+	if err != nil {
+		logrus.WithField("BatchMessageID", batchMessageId).WithError(err).Error("message batch progress error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageBatchProgressWithError(batchMessageId string) (*BatchMessageProgress, error) {
+	return c.MessageBatchProgressWithContext(context.Background(), batchMessageId)
+}
+
+func (c AppClient) MessageBatchProgressWithContext(ctx context.Context, batchMessageId string) (*BatchMessageProgress, error) {
+	resp, err := c.RequestWithContext(ctx, "get", "open-apis/im/v1/batch_messages/"+batchMessageId+"/get_progress", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &BatchMessageProgress{}
+	if send, ok := resp["batch_message_send_progress"].(map[string]any); ok {
+		progress.ValidUserCount = int(getInMap(send, "valid_user_ids_count", float64(0)).(float64))
+		progress.SuccessUserCount = int(getInMap(send, "success_user_ids_count", float64(0)).(float64))
+		progress.ReadUserCount = int(getInMap(send, "read_user_ids_count", float64(0)).(float64))
+	}
+	if recall, ok := resp["batch_message_recall_progress"].(map[string]any); ok {
+		progress.Recalled = getInMap(recall, "recall", false).(bool)
+		progress.RecallCount = int(getInMap(recall, "recall_count", float64(0)).(float64))
+	}
+	return progress, nil
+}
+
+// Recall all the messages of a batch message, the recalling goes on in background, track it by MessageBatchProgress
+func (c AppClient) MessageBatchRecall(batchMessageId string) {
+	if err := c.MessageBatchRecallWithError(batchMessageId); err != nil {
+		logrus.WithField("BatchMessageID", batchMessageId).WithError(err).Error("message batch recall error")
+	}
+}
+
+func (c AppClient) MessageBatchRecallWithError(batchMessageId string) error {
+	return c.MessageBatchRecallWithContext(context.Background(), batchMessageId)
+}
+
+func (c AppClient) MessageBatchRecallWithContext(ctx context.Context, batchMessageId string) error {
+	_, err := c.RequestWithContext(ctx, "delete", "open-apis/im/v1/batch_messages/"+batchMessageId, nil, nil, nil)
+	return err
+}
@@ -46,6 +46,24 @@ func (card *MessageCard) String() (string, error) {
 	return string(data), err
 }
 
+func (card *MessageCard) UnmarshalJSON(data []byte) error {
+	type messageCard MessageCard
+	raw := struct {
+		*messageCard
+		Elements []json.RawMessage `json:"elements"`
+	}{messageCard: (*messageCard)(card)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	elements, err := parseMessageCardElementsAs[MessageCardElement](raw.Elements, "element")
+	if err != nil {
+		return err
+	}
+	card.Elements = elements
+	return nil
+}
+
 type MessageCardConfig struct {
 	EnableForward *bool `json:"enable_forward,omitempty"`
 	UpdateMulti   *bool `json:"update_multi,omitempty"`
@@ -322,6 +340,24 @@ func (column *MessageCardColumn) MarshalJSON() ([]byte, error) {
 	return messageCardElementJSON(column)
 }
 
+func (column *MessageCardColumn) UnmarshalJSON(data []byte) error {
+	type messageCardColumn MessageCardColumn
+	raw := struct {
+		*messageCardColumn
+		Elements []json.RawMessage `json:"elements"`
+	}{messageCardColumn: (*messageCardColumn)(column)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	elements, err := parseMessageCardElementsAs[MessageCardElement](raw.Elements, "element")
+	if err != nil {
+		return err
+	}
+	column.Elements = elements
+	return nil
+}
+
 type MessageCardDiv struct {
 	Text   MessageCardText     `json:"text,omitempty"`
 	Fields []*MessageCardField `json:"fields,omitempty"`
@@ -362,6 +398,30 @@ func (div *MessageCardDiv) MarshalJSON() ([]byte, error) {
 	return messageCardElementJSON(div)
 }
 
+func (div *MessageCardDiv) UnmarshalJSON(data []byte) error {
+	type messageCardDiv MessageCardDiv
+	raw := struct {
+		*messageCardDiv
+		Text  json.RawMessage `json:"text"`
+		Extra json.RawMessage `json:"extra"`
+	}{messageCardDiv: (*messageCardDiv)(div)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	text, err := parseMessageCardElementAs[MessageCardText](raw.Text, "text")
+	if err != nil {
+		return err
+	}
+	extra, err := parseMessageCardElementAs[MessageCardExtra](raw.Extra, "extra")
+	if err != nil {
+		return err
+	}
+	div.Text = text
+	div.Extra = extra
+	return nil
+}
+
 type MessageCardField struct {
 	IsShort *bool           `json:"is_short,omitempty"`
 	Text    MessageCardText `json:"text,omitempty"`
@@ -385,6 +445,24 @@ func (field *MessageCardField) Build() *MessageCardField {
 	return field
 }
 
+func (field *MessageCardField) UnmarshalJSON(data []byte) error {
+	type messageCardField MessageCardField
+	raw := struct {
+		*messageCardField
+		Text json.RawMessage `json:"text"`
+	}{messageCardField: (*messageCardField)(field)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	text, err := parseMessageCardElementAs[MessageCardText](raw.Text, "text")
+	if err != nil {
+		return err
+	}
+	field.Text = text
+	return nil
+}
+
 type MessageCardExtra interface {
 	MessageCardElement
 	IsExtra()
@@ -572,6 +650,24 @@ func (image *MessageCardImage) MarshalJSON() ([]byte, error) {
 	return messageCardElementJSON(image)
 }
 
+func (image *MessageCardImage) UnmarshalJSON(data []byte) error {
+	type messageCardImage MessageCardImage
+	raw := struct {
+		*messageCardImage
+		Title json.RawMessage `json:"title"`
+	}{messageCardImage: (*messageCardImage)(image)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	title, err := parseMessageCardElementAs[MessageCardText](raw.Title, "text")
+	if err != nil {
+		return err
+	}
+	image.Title = title
+	return nil
+}
+
 func (image *MessageCardImage) IsNote() {}
 
 func (image *MessageCardImage) IsExtra() {}
@@ -601,6 +697,22 @@ func (note *MessageCardNote) MarshalJSON() ([]byte, error) {
 	return messageCardElementJSON(note)
 }
 
+func (note *MessageCardNote) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Elements []json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	elements, err := parseMessageCardElementsAs[MessageCardNoteElement](raw.Elements, "note element")
+	if err != nil {
+		return err
+	}
+	note.Elements = elements
+	return nil
+}
+
 type MessageCardNoteElement interface {
 	MessageCardElement
 	IsNote()
@@ -648,6 +760,24 @@ func (action *MessageCardAction) MarshalJSON() ([]byte, error) {
 	return messageCardElementJSON(action)
 }
 
+func (action *MessageCardAction) UnmarshalJSON(data []byte) error {
+	type messageCardAction MessageCardAction
+	raw := struct {
+		*messageCardAction
+		Actions []json.RawMessage `json:"actions"`
+	}{messageCardAction: (*messageCardAction)(action)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	actions, err := parseMessageCardElementsAs[MessageCardActionElement](raw.Actions, "action")
+	if err != nil {
+		return err
+	}
+	action.Actions = actions
+	return nil
+}
+
 type MessageCardActionElement interface {
 	MessageCardElement
 	IsAction()
@@ -984,9 +1114,7 @@ func (selectPerson *MessageCardSelectPerson) MarshalJSON() ([]byte, error) {
 	if selectPerson.InitialOption == nil && selectPerson.PlaceHolder == nil {
 		return nil, errors.New("placeholder is required")
 	}
-	if len(selectPerson.Options) == 0 {
-		return nil, errors.New("options is required")
-	}
+	// the options are optional, every member of the chat can be selected without them
 	return messageCardElementJSON(selectPerson)
 }
 
@@ -1059,6 +1187,24 @@ func (button *MessageCardButton) MarshalJSON() ([]byte, error) {
 	return messageCardElementJSON(button)
 }
 
+func (button *MessageCardButton) UnmarshalJSON(data []byte) error {
+	type messageCardButton MessageCardButton
+	raw := struct {
+		*messageCardButton
+		Text json.RawMessage `json:"text"`
+	}{messageCardButton: (*messageCardButton)(button)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	text, err := parseMessageCardElementAs[MessageCardText](raw.Text, "text")
+	if err != nil {
+		return err
+	}
+	button.Text = text
+	return nil
+}
+
 func (button *MessageCardButton) IsAction() {}
 
 func (button *MessageCardButton) IsExtra() {}
@@ -0,0 +1,92 @@
+package feishuapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// The elements which can be decoded from JSON, by their tags
+var messageCardElementFactories = map[string]func() MessageCardElement{
+	"plain_text":      func() MessageCardElement { return NewMessageCardPlainText() },
+	"lark_md":         func() MessageCardElement { return NewMessageCardLarkMarkdown() },
+	"column_set":      func() MessageCardElement { return NewMessageCardColumnSet() },
+	"column":          func() MessageCardElement { return NewMessageCardColumn() },
+	"div":             func() MessageCardElement { return NewMessageCardDiv() },
+	"markdown":        func() MessageCardElement { return NewMessageCardMarkdown() },
+	"hr":              func() MessageCardElement { return NewMessageCardHr() },
+	"img":             func() MessageCardElement { return NewMessageCardImage() },
+	"note":            func() MessageCardElement { return NewMessageCardNote() },
+	"action":          func() MessageCardElement { return NewMessageCardAction() },
+	"date_picker":     func() MessageCardElement { return NewMessageCardDatePicker() },
+	"picker_time":     func() MessageCardElement { return NewMessageCardPickerTime() },
+	"picker_datetime": func() MessageCardElement { return NewMessageCardPickerDateTime() },
+	"overflow":        func() MessageCardElement { return NewMessageCardOverflow() },
+	"select_static":   func() MessageCardElement { return NewMessageCardSelectStatic() },
+	"select_person":   func() MessageCardElement { return NewMessageCardSelectPerson() },
+	"button":          func() MessageCardElement { return NewMessageCardButton() },
+	"repeat":          func() MessageCardElement { return NewMessageCardRepeat() },
+}
+
+// Parse a message card from its JSON, such as the result of MessageCard.String().
+// Every element is decoded into its builder type by its tag, so the card can be modified and sent again
+func ParseMessageCard(data []byte) (*MessageCard, error) {
+	card := NewMessageCard()
+	if err := json.Unmarshal(data, card); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// Decode an element by its tag, nil if data is empty or null
+func parseMessageCardElement(data json.RawMessage) (MessageCardElement, error) {
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var header struct {
+		Tag string `json:"tag"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	factory, ok := messageCardElementFactories[header.Tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown message card element tag %q", header.Tag)
+	}
+
+	element := factory()
+	if err := json.Unmarshal(data, element); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", header.Tag, err)
+	}
+	return element, nil
+}
+
+// Decode an element which should be T, such as MessageCardText, kind names T in the error
+func parseMessageCardElementAs[T MessageCardElement](data json.RawMessage, kind string) (T, error) {
+	var zero T
+	element, err := parseMessageCardElement(data)
+	if err != nil || element == nil {
+		return zero, err
+	}
+	v, ok := element.(T)
+	if !ok {
+		return zero, fmt.Errorf("%s can't be used as %s", element.Tag(), kind)
+	}
+	return v, nil
+}
+
+func parseMessageCardElementsAs[T MessageCardElement](data []json.RawMessage, kind string) ([]T, error) {
+	if data == nil {
+		return nil, nil
+	}
+	elements := make([]T, 0, len(data))
+	for _, v := range data {
+		element, err := parseMessageCardElementAs[T](v, kind)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
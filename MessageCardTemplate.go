@@ -0,0 +1,310 @@
+package feishuapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// MessageCardTemplateRef is a card made in the Feishu card builder, which is sent by its template_id.
+// Send it by MessageSend with Interactive and the result of String()
+type MessageCardTemplateRef struct {
+	TemplateId  string
+	VersionName string
+	Variables   map[string]any
+}
+
+func NewMessageCardTemplateRef(templateId string) *MessageCardTemplateRef {
+	return &MessageCardTemplateRef{TemplateId: templateId}
+}
+
+// Use a published version of the template instead of the latest one, such as "1.0.0"
+func (ref *MessageCardTemplateRef) WithVersionName(versionName string) *MessageCardTemplateRef {
+	ref.VersionName = versionName
+	return ref
+}
+
+func (ref *MessageCardTemplateRef) WithVariables(variables map[string]any) *MessageCardTemplateRef {
+	ref.Variables = variables
+	return ref
+}
+
+func (ref *MessageCardTemplateRef) WithVariable(name string, value any) *MessageCardTemplateRef {
+	if ref.Variables == nil {
+		ref.Variables = make(map[string]any)
+	}
+	ref.Variables[name] = value
+	return ref
+}
+
+func (ref *MessageCardTemplateRef) Build() *MessageCardTemplateRef {
+	return ref
+}
+
+func (ref *MessageCardTemplateRef) String() (string, error) {
+	if ref.TemplateId == "" {
+		return "", errors.New("template_id is required")
+	}
+
+	data := make(map[string]any)
+	data["template_id"] = ref.TemplateId
+	if ref.VersionName != "" {
+		data["template_version_name"] = ref.VersionName
+	}
+	if len(ref.Variables) != 0 {
+		data["template_variable"] = ref.Variables
+	}
+
+	content, err := json.Marshal(map[string]any{
+		"type": "template",
+		"data": data,
+	})
+	return string(content), err
+}
+
+// MessageCardRepeat repeats its elements for every item of a list variable when a MessageCardTemplate is rendered.
+// The item is named "item" in the elements, unless another name is given by WithAs
+type MessageCardRepeat struct {
+	Items    string               `json:"items,omitempty"`
+	As       string               `json:"as,omitempty"`
+	Elements []MessageCardElement `json:"elements,omitempty"`
+}
+
+func NewMessageCardRepeat() *MessageCardRepeat {
+	return &MessageCardRepeat{}
+}
+
+// The name of the list variable
+func (repeat *MessageCardRepeat) WithItems(items string) *MessageCardRepeat {
+	repeat.Items = items
+	return repeat
+}
+
+func (repeat *MessageCardRepeat) WithAs(as string) *MessageCardRepeat {
+	repeat.As = as
+	return repeat
+}
+
+func (repeat *MessageCardRepeat) WithElements(elements []MessageCardElement) *MessageCardRepeat {
+	repeat.Elements = elements
+	return repeat
+}
+
+func (repeat *MessageCardRepeat) Build() *MessageCardRepeat {
+	return repeat
+}
+
+func (repeat *MessageCardRepeat) Tag() string {
+	return "repeat"
+}
+
+func (repeat *MessageCardRepeat) MarshalJSON() ([]byte, error) {
+	if repeat.Items == "" {
+		return nil, errors.New("items is required")
+	}
+	return messageCardElementJSON(repeat)
+}
+
+func (repeat *MessageCardRepeat) UnmarshalJSON(data []byte) error {
+	type messageCardRepeat MessageCardRepeat
+	raw := struct {
+		*messageCardRepeat
+		Elements []json.RawMessage `json:"elements"`
+	}{messageCardRepeat: (*messageCardRepeat)(repeat)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	elements, err := parseMessageCardElementsAs[MessageCardElement](raw.Elements, "element")
+	if err != nil {
+		return err
+	}
+	repeat.Elements = elements
+	return nil
+}
+
+// a repeat can be put in the elements of a note and the actions of an action of a MessageCardTemplate,
+// Validate rejects it in a card which isn't rendered
+func (repeat *MessageCardRepeat) IsNote() {}
+
+func (repeat *MessageCardRepeat) IsAction() {}
+
+// The elements whose content can contain ${var} placeholders
+var messageCardTemplateTextTags = map[string]bool{
+	"plain_text": true,
+	"lark_md":    true,
+	"markdown":   true,
+}
+
+// ${name}, ${name.field}
+var messageCardTemplateVariable = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z0-9_]+)*)\}`)
+
+// MessageCardTemplate is a card whose text contains ${var} placeholders, and whose elements can be repeated by MessageCardRepeat.
+// The placeholders are replaced in the content of plain_text, lark_md and markdown elements
+type MessageCardTemplate struct {
+	card *MessageCard
+}
+
+func NewMessageCardTemplate(card *MessageCard) *MessageCardTemplate {
+	return &MessageCardTemplate{card: card}
+}
+
+// Parse a template from its JSON, the loops are written as {"tag": "repeat", "items": "...", "elements": [...]}
+func ParseMessageCardTemplate(data []byte) (*MessageCardTemplate, error) {
+	card, err := ParseMessageCard(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewMessageCardTemplate(card), nil
+}
+
+// Build a new card from the template, the template itself is not changed.
+// The values of a variable can be nested maps, such as ${alert.host}, and a list variable should be a slice
+func (t *MessageCardTemplate) Render(vars map[string]any) (*MessageCard, error) {
+	data, err := json.Marshal(t.card)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	rendered, err := renderMessageCardTemplate(tree, vars)
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.Marshal(rendered)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMessageCard(data)
+}
+
+// Render the decoded JSON of a card, value isn't modified
+func renderMessageCardTemplate(value any, vars map[string]any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		tag, _ := v["tag"].(string)
+		if tag == "repeat" {
+			return nil, errors.New("repeat can only be used in a list of elements")
+		}
+
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			if content, ok := child.(string); ok && k == "content" && messageCardTemplateTextTags[tag] {
+				substituted, err := substituteMessageCardVariables(content, vars)
+				if err != nil {
+					return nil, err
+				}
+				out[k] = substituted
+				continue
+			}
+			rendered, err := renderMessageCardTemplate(child, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, child := range v {
+			if repeat, ok := child.(map[string]any); ok && repeat["tag"] == "repeat" {
+				expanded, err := renderMessageCardRepeat(repeat, vars)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, expanded...)
+				continue
+			}
+			rendered, err := renderMessageCardTemplate(child, vars)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rendered)
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// Render the elements of a repeat once for every item of the list
+func renderMessageCardRepeat(repeat map[string]any, vars map[string]any) ([]any, error) {
+	name, _ := repeat["items"].(string)
+	as, _ := repeat["as"].(string)
+	if as == "" {
+		as = "item"
+	}
+	elements, _ := repeat["elements"].([]any)
+
+	list, err := lookupMessageCardVariable(vars, name)
+	if err != nil {
+		return nil, err
+	}
+	items := reflect.ValueOf(list)
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return nil, fmt.Errorf("card template variable %q is %T, not a list", name, list)
+	}
+
+	var out []any
+	for i := 0; i < items.Len(); i++ {
+		scope := make(map[string]any, len(vars)+1)
+		for k, v := range vars {
+			scope[k] = v
+		}
+		scope[as] = items.Index(i).Interface()
+
+		rendered, err := renderMessageCardTemplate(elements, scope)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rendered.([]any)...)
+	}
+	return out, nil
+}
+
+func substituteMessageCardVariables(content string, vars map[string]any) (string, error) {
+	var err error
+	result := messageCardTemplateVariable.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := placeholder[2 : len(placeholder)-1]
+		value, lookupErr := lookupMessageCardVariable(vars, name)
+		if lookupErr != nil {
+			if err == nil {
+				err = lookupErr
+			}
+			return placeholder
+		}
+		return fmt.Sprint(value)
+	})
+	return result, err
+}
+
+// Look up a variable by its dotted name, the fields are looked up in the maps with string keys
+func lookupMessageCardVariable(vars map[string]any, name string) (any, error) {
+	path := strings.Split(name, ".")
+	value, ok := vars[path[0]]
+	if !ok {
+		return nil, fmt.Errorf("card template variable %q is missing", name)
+	}
+	for _, field := range path[1:] {
+		m := reflect.ValueOf(value)
+		if m.Kind() != reflect.Map || m.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("card template variable %q: %T has no field %s", name, value, field)
+		}
+		v := m.MapIndex(reflect.ValueOf(field).Convert(m.Type().Key()))
+		if !v.IsValid() {
+			return nil, fmt.Errorf("card template variable %q is missing", name)
+		}
+		value = v.Interface()
+	}
+	return value, nil
+}
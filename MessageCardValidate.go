@@ -0,0 +1,360 @@
+package feishuapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// the limit of the card JSON
+	messageCardMaxSize = 30 * 1024
+	// the limit of the options in an overflow
+	messageCardMaxOverflowOptions = 10
+	messageCardMinColumnWeight    = 1
+	messageCardMaxColumnWeight    = 5
+)
+
+// The tags of the elements which are allowed in the elements of a card or a column, a note, an action and the extra of a div
+var (
+	messageCardBlockTags  = []string{"div", "markdown", "hr", "img", "note", "action", "column_set"}
+	messageCardNoteTags   = []string{"plain_text", "lark_md", "img"}
+	messageCardActionTags = []string{"button", "overflow", "select_static", "select_person", "date_picker", "picker_time", "picker_datetime"}
+	messageCardExtraTags  = append([]string{"img"}, messageCardActionTags...)
+)
+
+// A rule of the card which is broken, Path locates the element, such as "elements[1].actions[0].text"
+type MessageCardValidationError struct {
+	Path string
+	Msg  string
+}
+
+func (e *MessageCardValidationError) Error() string {
+	if e.Path == "" {
+		return e.Msg
+	}
+	return e.Path + ": " + e.Msg
+}
+
+type MessageCardValidationErrors []*MessageCardValidationError
+
+func (errs MessageCardValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Check the card against the rules of Feishu before sending it, nil if the card is valid.
+// Otherwise the error is MessageCardValidationErrors, which lists every broken rule
+func (card *MessageCard) Validate() error {
+	v := &messageCardValidator{}
+
+	if card.Header != nil && (card.Header.Title == nil || card.Header.Title.Content == "") {
+		v.addf("header.title", "title is required")
+	}
+	if len(card.Elements) == 0 {
+		v.addf("elements", "elements is required")
+	}
+	for i, element := range card.Elements {
+		v.block(fmt.Sprintf("elements[%d]", i), element, "the elements of a card")
+	}
+
+	// the size is only meaningful when the card can be marshaled
+	if len(v.errs) == 0 {
+		data, err := json.Marshal(card)
+		if err != nil {
+			v.addf("", "marshal card: %v", err)
+		} else if len(data) > messageCardMaxSize {
+			v.addf("", "card is %d bytes, larger than %d bytes", len(data), messageCardMaxSize)
+		}
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type messageCardValidator struct {
+	errs MessageCardValidationErrors
+}
+
+func (v *messageCardValidator) addf(path string, format string, args ...any) {
+	v.errs = append(v.errs, &MessageCardValidationError{
+		Path: path,
+		Msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Check that the element is one of the tags, return false if it isn't
+func (v *messageCardValidator) allowed(path string, element MessageCardElement, where string, tags []string) bool {
+	// a repeat is expanded by MessageCardTemplate.Render, Feishu doesn't know it
+	if _, ok := element.(*MessageCardRepeat); ok {
+		v.addf(path, "repeat can only be used in a MessageCardTemplate, render the template before sending the card")
+		return false
+	}
+	for _, tag := range tags {
+		if element.Tag() == tag {
+			return true
+		}
+	}
+	v.addf(path, "%s is not allowed in %s, only %s", element.Tag(), where, strings.Join(tags, ", "))
+	return false
+}
+
+// Report whether the element is nil, including a nil pointer of a known element,
+// such as (*MessageCardDiv)(nil), which isn't equal to a nil interface
+func isNilMessageCardElement(element MessageCardElement) bool {
+	switch e := element.(type) {
+	case nil:
+		return true
+	case *MessageCardPlainText:
+		return e == nil
+	case *MessageCardLarkMarkdown:
+		return e == nil
+	case *MessageCardHr:
+		return e == nil
+	case *MessageCardDiv:
+		return e == nil
+	case *MessageCardMarkdown:
+		return e == nil
+	case *MessageCardImage:
+		return e == nil
+	case *MessageCardNote:
+		return e == nil
+	case *MessageCardAction:
+		return e == nil
+	case *MessageCardColumnSet:
+		return e == nil
+	case *MessageCardColumn:
+		return e == nil
+	case *MessageCardButton:
+		return e == nil
+	case *MessageCardOverflow:
+		return e == nil
+	case *MessageCardSelectStatic:
+		return e == nil
+	case *MessageCardSelectPerson:
+		return e == nil
+	case *MessageCardDatePicker:
+		return e == nil
+	case *MessageCardPickerTime:
+		return e == nil
+	case *MessageCardPickerDateTime:
+		return e == nil
+	case *MessageCardRepeat:
+		return e == nil
+	}
+	return false
+}
+
+// Check an element which is put in the elements of a card or a column, where the interactive and text elements can't be
+func (v *messageCardValidator) block(path string, element MessageCardElement, where string) {
+	if isNilMessageCardElement(element) {
+		v.addf(path, "element is nil")
+		return
+	}
+	if v.allowed(path, element, where, messageCardBlockTags) {
+		v.element(path, element)
+	}
+}
+
+func (v *messageCardValidator) element(path string, element MessageCardElement) {
+	if isNilMessageCardElement(element) {
+		v.addf(path, "element is nil")
+		return
+	}
+	switch e := element.(type) {
+	case *MessageCardPlainText, *MessageCardLarkMarkdown, *MessageCardHr:
+	case *MessageCardDiv:
+		v.div(path, e)
+	case *MessageCardMarkdown:
+		if e.Content == "" {
+			v.addf(path+".content", "content is required")
+		}
+	case *MessageCardImage:
+		v.image(path, e)
+	case *MessageCardNote:
+		v.note(path, e)
+	case *MessageCardAction:
+		v.action(path, e)
+	case *MessageCardColumnSet:
+		v.columnSet(path, e)
+	case *MessageCardColumn:
+		v.addf(path, "column is only allowed in the columns of a column_set")
+	case *MessageCardButton:
+		v.button(path, e)
+	case *MessageCardOverflow:
+		v.overflow(path, e)
+	case *MessageCardSelectStatic:
+		v.selectStatic(path, e)
+	case *MessageCardSelectPerson:
+		v.selectPerson(path, e)
+	case *MessageCardDatePicker:
+		v.datePicker(path, e.MessageCardDatePickerBase, "initial_date")
+	case *MessageCardPickerTime:
+		v.datePicker(path, e.MessageCardDatePickerBase, "initial_time")
+	case *MessageCardPickerDateTime:
+		v.datePicker(path, e.MessageCardDatePickerBase, "initial_datetime")
+	default:
+		v.addf(path, "unknown %s element %T", element.Tag(), element)
+	}
+}
+
+func (v *messageCardValidator) div(path string, div *MessageCardDiv) {
+	if div.Text == nil && len(div.Fields) == 0 {
+		v.addf(path, "text or fields is required")
+	}
+	for i, field := range div.Fields {
+		if field == nil || field.Text == nil {
+			v.addf(fmt.Sprintf("%s.fields[%d].text", path, i), "text is required")
+		}
+	}
+	if div.Extra != nil && isNilMessageCardElement(div.Extra) {
+		v.addf(path+".extra", "element is nil")
+	} else if div.Extra != nil && v.allowed(path+".extra", div.Extra, "the extra of a div", messageCardExtraTags) {
+		v.element(path+".extra", div.Extra)
+	}
+}
+
+func (v *messageCardValidator) image(path string, image *MessageCardImage) {
+	if image.ImageKey == nil || *image.ImageKey == "" {
+		v.addf(path+".img_key", "image key is required")
+	}
+	if image.Alt == nil {
+		v.addf(path+".alt", "alt is required")
+	}
+}
+
+func (v *messageCardValidator) note(path string, note *MessageCardNote) {
+	if len(note.Elements) == 0 {
+		v.addf(path+".elements", "elements is required")
+	}
+	for i, element := range note.Elements {
+		elementPath := fmt.Sprintf("%s.elements[%d]", path, i)
+		if isNilMessageCardElement(element) {
+			v.addf(elementPath, "element is nil")
+			continue
+		}
+		if v.allowed(elementPath, element, "a note", messageCardNoteTags) {
+			v.element(elementPath, element)
+		}
+	}
+}
+
+func (v *messageCardValidator) action(path string, action *MessageCardAction) {
+	if len(action.Actions) == 0 {
+		v.addf(path+".actions", "actions is required")
+	}
+	for i, element := range action.Actions {
+		elementPath := fmt.Sprintf("%s.actions[%d]", path, i)
+		if isNilMessageCardElement(element) {
+			v.addf(elementPath, "element is nil")
+			continue
+		}
+		if v.allowed(elementPath, element, "an action", messageCardActionTags) {
+			v.element(elementPath, element)
+		}
+	}
+}
+
+func (v *messageCardValidator) columnSet(path string, columnSet *MessageCardColumnSet) {
+	if columnSet.FlexMode == nil {
+		v.addf(path+".flex_mode", "flex_mode is required")
+	}
+	for i := range columnSet.Columns {
+		v.column(fmt.Sprintf("%s.columns[%d]", path, i), &columnSet.Columns[i])
+	}
+}
+
+func (v *messageCardValidator) column(path string, column *MessageCardColumn) {
+	weighted := column.Width != nil && *column.Width == WidthWeighted
+	if column.Weight != nil && !weighted {
+		v.addf(path+".weight", "weight only works when width is weighted")
+	}
+	if weighted && column.Weight != nil && (*column.Weight < messageCardMinColumnWeight || *column.Weight > messageCardMaxColumnWeight) {
+		v.addf(path+".weight", "weight should be between %d and %d, got %d", messageCardMinColumnWeight, messageCardMaxColumnWeight, *column.Weight)
+	}
+	for i, element := range column.Elements {
+		elementPath := fmt.Sprintf("%s.elements[%d]", path, i)
+		if !isNilMessageCardElement(element) && element.Tag() == "column_set" {
+			v.addf(elementPath, "column_set can't be nested in a column")
+			continue
+		}
+		v.block(elementPath, element, "a column")
+	}
+}
+
+func (v *messageCardValidator) button(path string, button *MessageCardButton) {
+	if button.Text == nil {
+		v.addf(path+".text", "text is required")
+	}
+	if button.URL != nil && button.MultiURL != nil {
+		v.addf(path, "url and multi_url can not be set at the same time")
+	}
+}
+
+func (v *messageCardValidator) options(path string, options []MessageCardOption, textRequired bool) {
+	if len(options) == 0 {
+		v.addf(path, "options is required")
+	}
+	for i, option := range options {
+		if textRequired && option.Text == nil {
+			v.addf(fmt.Sprintf("%s[%d].text", path, i), "text is required")
+		}
+	}
+}
+
+func (v *messageCardValidator) overflow(path string, overflow *MessageCardOverflow) {
+	v.options(path+".options", overflow.Options, true)
+	if len(overflow.Options) > messageCardMaxOverflowOptions {
+		v.addf(path+".options", "at most %d options are allowed, got %d", messageCardMaxOverflowOptions, len(overflow.Options))
+	}
+}
+
+// the options of a select_person are optional and have no text, they limit the persons which can be selected
+func (v *messageCardValidator) selectMenu(path string, base *MessageCardSelectMenuBase, static bool) {
+	if base == nil {
+		v.addf(path, "placeholder is required")
+		return
+	}
+	if base.InitialOption == nil && base.PlaceHolder == nil {
+		v.addf(path+".placeholder", "placeholder is required")
+	}
+	if static || len(base.Options) != 0 {
+		v.options(path+".options", base.Options, static)
+	}
+	if base.InitialOption != nil && len(base.Options) != 0 {
+		for _, option := range base.Options {
+			if option.Value != nil && *option.Value == *base.InitialOption {
+				return
+			}
+		}
+		v.addf(path+".initial_option", "%q is not the value of any option", *base.InitialOption)
+	}
+}
+
+func (v *messageCardValidator) selectStatic(path string, selectStatic *MessageCardSelectStatic) {
+	v.selectMenu(path, selectStatic.MessageCardSelectMenuBase, true)
+}
+
+func (v *messageCardValidator) selectPerson(path string, selectPerson *MessageCardSelectPerson) {
+	v.selectMenu(path, selectPerson.MessageCardSelectMenuBase, false)
+}
+
+// initialName is the field of the initial value which the picker uses
+func (v *messageCardValidator) datePicker(path string, base *MessageCardDatePickerBase, initialName string) {
+	if base == nil {
+		v.addf(path, "%s or placeholder is required", initialName)
+		return
+	}
+	initial := map[string]*string{
+		"initial_date":     base.InitialDate,
+		"initial_time":     base.InitialTime,
+		"initial_datetime": base.InitialDateTime,
+	}[initialName]
+	if initial == nil && base.PlaceHolder == nil {
+		v.addf(path, "%s or placeholder is required", initialName)
+	}
+}
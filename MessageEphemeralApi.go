@@ -0,0 +1,64 @@
+package feishuapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Send a message card to a chat group which is visible to only one member of it, return the message id.
+// receiverIdType can only be UserOpenId, UserUserId or UserEmail
+func (c AppClient) MessageSendEphemeralCard(chatId string, receiverIdType MsgReceiverType, receiverId string, card *MessageCard) string {
+	result, err := c.MessageSendEphemeralCardWithError(chatId, receiverIdType, receiverId, card)
+	if err != nil {
+		logrus.WithField("ChatID", chatId).WithError(err).Error("message send ephemeral card error")
+		return ""
+	}
+	return result
+}
+
+func (c AppClient) MessageSendEphemeralCardWithError(chatId string, receiverIdType MsgReceiverType, receiverId string, card *MessageCard) (string, error) {
+	return c.MessageSendEphemeralCardWithContext(context.Background(), chatId, receiverIdType, receiverId, card)
+}
+
+func (c AppClient) MessageSendEphemeralCardWithContext(ctx context.Context, chatId string, receiverIdType MsgReceiverType, receiverId string, card *MessageCard) (string, error) {
+	if receiverIdType != UserOpenId && receiverIdType != UserUserId && receiverIdType != UserEmail {
+		return "", errors.New("the receiver of an ephemeral card should be identified by open_id, user_id or email")
+	}
+
+	body := make(map[string]any)
+	body["chat_id"] = chatId
+	body[string(receiverIdType)] = receiverId
+	body["msg_type"] = string(Interactive)
+	body["card"] = card
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/ephemeral/v1/send", nil, nil, body)
+	if err != nil {
+		return "", err
+	}
+	messageId, ok := resp["message_id"].(string)
+	if !ok {
+		return "", errors.New("message_id is missing in response")
+	}
+	return messageId, nil
+}
+
+// Delete an ephemeral card sent by MessageSendEphemeralCard
+func (c AppClient) MessageDeleteEphemeralCard(messageId string) {
+	if err := c.MessageDeleteEphemeralCardWithError(messageId); err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message delete ephemeral card error")
+	}
+}
+
+func (c AppClient) MessageDeleteEphemeralCardWithError(messageId string) error {
+	return c.MessageDeleteEphemeralCardWithContext(context.Background(), messageId)
+}
+
+func (c AppClient) MessageDeleteEphemeralCardWithContext(ctx context.Context, messageId string) error {
+	body := make(map[string]string)
+	body["message_id"] = messageId
+
+	_, err := c.RequestWithContext(ctx, "post", "open-apis/ephemeral/v1/delete", nil, nil, body)
+	return err
+}
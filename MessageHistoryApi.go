@@ -0,0 +1,224 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TextMessageContent struct {
+	// the mentions are written as their keys, such as "@_user_1"
+	Text string `json:"text"`
+}
+
+type ImageMessageContent struct {
+	ImageKey string `json:"image_key"`
+}
+
+// The content of File, Audio and Media messages
+type FileMessageContent struct {
+	FileKey  string `json:"file_key"`
+	FileName string `json:"file_name"`
+	// the cover of a Media message
+	ImageKey string `json:"image_key"`
+	// the length of an Audio or Media message in milliseconds
+	Duration int `json:"duration"`
+}
+
+// An element of a received post or message card, only the fields of its Tag are set
+type MessageContentElement struct {
+	Tag       string   `json:"tag"`
+	Text      string   `json:"text"`
+	Href      string   `json:"href"`
+	UserId    string   `json:"user_id"`
+	UserName  string   `json:"user_name"`
+	ImageKey  string   `json:"image_key"`
+	FileKey   string   `json:"file_key"`
+	EmojiType string   `json:"emoji_type"`
+	Language  string   `json:"language"`
+	Style     []string `json:"style"`
+}
+
+type PostMessageContent struct {
+	Title string `json:"title"`
+	// the paragraphs of the post
+	Content [][]MessageContentElement `json:"content"`
+}
+
+// The content of an Interactive message, Feishu returns the text of the card instead of the card JSON
+type CardMessageContent struct {
+	Title    string                    `json:"title"`
+	Elements [][]MessageContentElement `json:"elements"`
+}
+
+// Parse the content of a message by its type, nil if the type isn't supported or the content is malformed
+func parseMessageContent(msgType MsgContentType, content string) any {
+	var v any
+	switch msgType {
+	case Text:
+		v = &TextMessageContent{}
+	case Post:
+		v = &PostMessageContent{}
+	case Image:
+		v = &ImageMessageContent{}
+	case File, Audio, Media:
+		v = &FileMessageContent{}
+	case Interactive:
+		v = &CardMessageContent{}
+	default:
+		return nil
+	}
+	if err := json.Unmarshal([]byte(content), v); err != nil {
+		return nil
+	}
+	return v
+}
+
+const messageListPageSize = 50
+
+// MessageIterator walks through the messages of a chat page by page, from the oldest to the newest
+//
+//	it := cli.MessageList(chatId, start, end)
+//	for it.Next() {
+//		archive(it.Message())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type MessageIterator struct {
+	client    AppClient
+	ctx       context.Context
+	query     map[string]any
+	page      []any
+	pageToken string
+	hasMore   bool
+	message   *Message
+	err       error
+}
+
+// List the messages of a chat group created between start and end, a zero start or end leaves that side open
+func (c AppClient) MessageList(chatId string, start time.Time, end time.Time) *MessageIterator {
+	return c.MessageListWithContext(context.Background(), chatId, start, end)
+}
+
+func (c AppClient) MessageListWithContext(ctx context.Context, chatId string, start time.Time, end time.Time) *MessageIterator {
+	query := make(map[string]any)
+	query["container_id_type"] = "chat"
+	query["container_id"] = chatId
+	query["sort_type"] = "ByCreateTimeAsc"
+	query["page_size"] = messageListPageSize
+	if !start.IsZero() {
+		query["start_time"] = strconv.FormatInt(start.Unix(), 10)
+	}
+	if !end.IsZero() {
+		query["end_time"] = strconv.FormatInt(end.Unix(), 10)
+	}
+
+	return &MessageIterator{
+		client:  c,
+		ctx:     ctx,
+		query:   query,
+		hasMore: true,
+	}
+}
+
+// Move to the next message, false when all the messages are walked through or an error occurs
+func (it *MessageIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil || !it.hasMore {
+			it.message = nil
+			return false
+		}
+		it.fetch()
+	}
+
+	data, _ := it.page[0].(map[string]any)
+	it.page = it.page[1:]
+	it.message = NewMessage(data)
+	return true
+}
+
+// The current message, only valid after Next returns true
+func (it *MessageIterator) Message() *Message {
+	return it.message
+}
+
+// The error which stops the walk, nil if all the messages are walked through
+func (it *MessageIterator) Err() error {
+	return it.err
+}
+
+func (it *MessageIterator) fetch() {
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+	if it.pageToken != "" {
+		it.query["page_token"] = it.pageToken
+	}
+
+	resp, err := it.client.RequestWithContext(it.ctx, "get", "open-apis/im/v1/messages", it.query, nil, nil)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page, _ = resp["items"].([]any)
+	it.hasMore, _ = resp["has_more"].(bool)
+	it.pageToken, _ = resp["page_token"].(string)
+	if it.pageToken == "" {
+		it.hasMore = false
+	}
+}
+
+type MessageResourceType string
+
+const (
+	MessageResourceImage MessageResourceType = "image"
+	// the resource of File, Audio and Media messages
+	MessageResourceFile MessageResourceType = "file"
+)
+
+type MessageResource struct {
+	// empty if Feishu doesn't tell the name
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// Download the image or file in a message, key is the image_key or file_key in the content of the message
+func (c AppClient) MessageResourceDownload(messageId string, key string, resourceType MessageResourceType) *MessageResource {
+	result, err := c.MessageResourceDownloadWithError(messageId, key, resourceType)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message resource download error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageResourceDownloadWithError(messageId string, key string, resourceType MessageResourceType) (*MessageResource, error) {
+	return c.MessageResourceDownloadWithContext(context.Background(), messageId, key, resourceType)
+}
+
+func (c AppClient) MessageResourceDownloadWithContext(ctx context.Context, messageId string, key string, resourceType MessageResourceType) (*MessageResource, error) {
+	query := make(map[string]any)
+	query["type"] = string(resourceType)
+
+	resp, data, err := c.requestBinary(ctx, "get", "open-apis/im/v1/messages/"+messageId+"/resources/"+key, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := &MessageResource{
+		ContentType: resp.Header.Get("Content-Type"),
+		Data:        data,
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		resource.FileName = params["filename"]
+	}
+	return resource, nil
+}
@@ -0,0 +1,342 @@
+package feishuapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+type MessagePostLocale string
+
+const (
+	PostZhCN MessagePostLocale = "zh_cn"
+	PostEnUS MessagePostLocale = "en_us"
+	PostJaJP MessagePostLocale = "ja_jp"
+)
+
+// MessagePost is the content of a rich text message, it holds a title and paragraphs for every locale
+type MessagePost struct {
+	Locales map[MessagePostLocale]*MessagePostContent
+}
+
+func NewMessagePost() *MessagePost {
+	return &MessagePost{
+		Locales: make(map[MessagePostLocale]*MessagePostContent),
+	}
+}
+
+func (post *MessagePost) WithContent(locale MessagePostLocale, content *MessagePostContent) *MessagePost {
+	post.Locales[locale] = content
+	return post
+}
+
+func (post *MessagePost) Build() *MessagePost {
+	return post
+}
+
+func (post *MessagePost) MarshalJSON() ([]byte, error) {
+	return json.Marshal(post.Locales)
+}
+
+func (post *MessagePost) String() (string, error) {
+	if len(post.Locales) == 0 {
+		return "", errors.New("content of at least one locale is required")
+	}
+	data, err := json.Marshal(post)
+	return string(data), err
+}
+
+type MessagePostContent struct {
+	Title   string                 `json:"title,omitempty"`
+	Content [][]MessagePostElement `json:"content"`
+}
+
+func NewMessagePostContent() *MessagePostContent {
+	return &MessagePostContent{}
+}
+
+func (content *MessagePostContent) WithTitle(title string) *MessagePostContent {
+	content.Title = title
+	return content
+}
+
+// Append a paragraph which is made of the elements in one line
+func (content *MessagePostContent) WithParagraph(elements ...MessagePostElement) *MessagePostContent {
+	content.Content = append(content.Content, elements)
+	return content
+}
+
+func (content *MessagePostContent) Build() *MessagePostContent {
+	return content
+}
+
+type MessagePostElement interface {
+	Tag() string
+	MarshalJSON() ([]byte, error)
+}
+
+func messagePostElementJSON(element MessagePostElement) ([]byte, error) {
+	data, err := struct2mapByReflect(element)
+	if err != nil {
+		return nil, err
+	}
+	data["tag"] = element.Tag()
+	return json.Marshal(data)
+}
+
+type MessagePostTextStyle string
+
+const (
+	PostTextBold        MessagePostTextStyle = "bold"
+	PostTextUnderline   MessagePostTextStyle = "underline"
+	PostTextLineThrough MessagePostTextStyle = "lineThrough"
+	PostTextItalic      MessagePostTextStyle = "italic"
+)
+
+type MessagePostText struct {
+	Text     string                 `json:"text,omitempty"`
+	UnEscape *bool                  `json:"un_escape,omitempty"`
+	Style    []MessagePostTextStyle `json:"style,omitempty"`
+}
+
+func NewMessagePostText() *MessagePostText {
+	return &MessagePostText{}
+}
+
+func (text *MessagePostText) WithText(content string) *MessagePostText {
+	text.Text = content
+	return text
+}
+
+// Decode the HTML entities in the text, e.g. "&nbsp;"
+func (text *MessagePostText) WithUnEscape(unEscape bool) *MessagePostText {
+	text.UnEscape = &unEscape
+	return text
+}
+
+func (text *MessagePostText) WithStyle(style ...MessagePostTextStyle) *MessagePostText {
+	text.Style = style
+	return text
+}
+
+func (text *MessagePostText) Build() *MessagePostText {
+	return text
+}
+
+func (text *MessagePostText) Tag() string {
+	return "text"
+}
+
+func (text *MessagePostText) MarshalJSON() ([]byte, error) {
+	return messagePostElementJSON(text)
+}
+
+type MessagePostLink struct {
+	Text  string                 `json:"text,omitempty"`
+	Href  string                 `json:"href,omitempty"`
+	Style []MessagePostTextStyle `json:"style,omitempty"`
+}
+
+func NewMessagePostLink() *MessagePostLink {
+	return &MessagePostLink{}
+}
+
+func (link *MessagePostLink) WithText(text string) *MessagePostLink {
+	link.Text = text
+	return link
+}
+
+func (link *MessagePostLink) WithHref(href string) *MessagePostLink {
+	link.Href = href
+	return link
+}
+
+func (link *MessagePostLink) WithStyle(style ...MessagePostTextStyle) *MessagePostLink {
+	link.Style = style
+	return link
+}
+
+func (link *MessagePostLink) Build() *MessagePostLink {
+	return link
+}
+
+func (link *MessagePostLink) Tag() string {
+	return "a"
+}
+
+func (link *MessagePostLink) MarshalJSON() ([]byte, error) {
+	if link.Href == "" {
+		return nil, errors.New("href is required")
+	}
+	return messagePostElementJSON(link)
+}
+
+type MessagePostAt struct {
+	// the open_id of the user, or "all" to mention everyone
+	UserId string                 `json:"user_id,omitempty"`
+	Style  []MessagePostTextStyle `json:"style,omitempty"`
+}
+
+func NewMessagePostAt() *MessagePostAt {
+	return &MessagePostAt{}
+}
+
+func (at *MessagePostAt) WithUserId(userId string) *MessagePostAt {
+	at.UserId = userId
+	return at
+}
+
+func (at *MessagePostAt) WithStyle(style ...MessagePostTextStyle) *MessagePostAt {
+	at.Style = style
+	return at
+}
+
+func (at *MessagePostAt) Build() *MessagePostAt {
+	return at
+}
+
+func (at *MessagePostAt) Tag() string {
+	return "at"
+}
+
+func (at *MessagePostAt) MarshalJSON() ([]byte, error) {
+	if at.UserId == "" {
+		return nil, errors.New("user_id is required")
+	}
+	return messagePostElementJSON(at)
+}
+
+// MessagePostImage must be the only element of its paragraph
+type MessagePostImage struct {
+	ImageKey string `json:"image_key,omitempty"`
+}
+
+func NewMessagePostImage() *MessagePostImage {
+	return &MessagePostImage{}
+}
+
+func (image *MessagePostImage) WithImageKey(imageKey string) *MessagePostImage {
+	image.ImageKey = imageKey
+	return image
+}
+
+func (image *MessagePostImage) Build() *MessagePostImage {
+	return image
+}
+
+func (image *MessagePostImage) Tag() string {
+	return "img"
+}
+
+func (image *MessagePostImage) MarshalJSON() ([]byte, error) {
+	if image.ImageKey == "" {
+		return nil, errors.New("image_key is required")
+	}
+	return messagePostElementJSON(image)
+}
+
+type MessagePostEmotion struct {
+	// the emoji type, e.g. "SMILE", see https://open.feishu.cn/document/server-docs/im-v1/message-reaction/emojis-introduce
+	EmojiType string `json:"emoji_type,omitempty"`
+}
+
+func NewMessagePostEmotion() *MessagePostEmotion {
+	return &MessagePostEmotion{}
+}
+
+func (emotion *MessagePostEmotion) WithEmojiType(emojiType string) *MessagePostEmotion {
+	emotion.EmojiType = emojiType
+	return emotion
+}
+
+func (emotion *MessagePostEmotion) Build() *MessagePostEmotion {
+	return emotion
+}
+
+func (emotion *MessagePostEmotion) Tag() string {
+	return "emotion"
+}
+
+func (emotion *MessagePostEmotion) MarshalJSON() ([]byte, error) {
+	if emotion.EmojiType == "" {
+		return nil, errors.New("emoji_type is required")
+	}
+	return messagePostElementJSON(emotion)
+}
+
+// MessagePostCodeBlock must be the only element of its paragraph
+type MessagePostCodeBlock struct {
+	// e.g. "GO", "PYTHON", "SHELL"
+	Language string `json:"language,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+func NewMessagePostCodeBlock() *MessagePostCodeBlock {
+	return &MessagePostCodeBlock{}
+}
+
+func (codeBlock *MessagePostCodeBlock) WithLanguage(language string) *MessagePostCodeBlock {
+	codeBlock.Language = language
+	return codeBlock
+}
+
+func (codeBlock *MessagePostCodeBlock) WithText(text string) *MessagePostCodeBlock {
+	codeBlock.Text = text
+	return codeBlock
+}
+
+func (codeBlock *MessagePostCodeBlock) Build() *MessagePostCodeBlock {
+	return codeBlock
+}
+
+func (codeBlock *MessagePostCodeBlock) Tag() string {
+	return "code_block"
+}
+
+func (codeBlock *MessagePostCodeBlock) MarshalJSON() ([]byte, error) {
+	return messagePostElementJSON(codeBlock)
+}
+
+// MessagePostHr must be the only element of its paragraph
+type MessagePostHr struct {
+}
+
+func NewMessagePostHr() *MessagePostHr {
+	return &MessagePostHr{}
+}
+
+func (hr *MessagePostHr) Build() *MessagePostHr {
+	return hr
+}
+
+func (hr *MessagePostHr) Tag() string {
+	return "hr"
+}
+
+func (hr *MessagePostHr) MarshalJSON() ([]byte, error) {
+	return messagePostElementJSON(hr)
+}
+
+// Send a rich text message to a person / chat group, return the message id
+func (c AppClient) MessageSendPost(receiveIdType MsgReceiverType, receiveId string, post *MessagePost) string {
+	result, err := c.MessageSendPostWithError(receiveIdType, receiveId, post)
+	if err != nil {
+		logrus.WithField("ReceiveID", receiveId).WithError(err).Error("message send post error")
+		return ""
+	}
+	return result
+}
+
+func (c AppClient) MessageSendPostWithError(receiveIdType MsgReceiverType, receiveId string, post *MessagePost) (string, error) {
+	return c.MessageSendPostWithContext(context.Background(), receiveIdType, receiveId, post)
+}
+
+func (c AppClient) MessageSendPostWithContext(ctx context.Context, receiveIdType MsgReceiverType, receiveId string, post *MessagePost) (string, error) {
+	content, err := post.String()
+	if err != nil {
+		return "", err
+	}
+	return c.MessageSendWithContext(ctx, receiveIdType, receiveId, Post, content)
+}
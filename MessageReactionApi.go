@@ -0,0 +1,218 @@
+package feishuapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type MessageReaction struct {
+	ReactionId string
+	// such as "THUMBSUP", "OK" and "DONE"
+	EmojiType  string
+	OperatorId string
+	// "user" for a user, whose OperatorId is open_id, "app" for a bot, whose OperatorId is app_id
+	OperatorType string
+	ActionTime   time.Time
+}
+
+// Create a new MessageReaction
+func NewMessageReaction(data map[string]any) *MessageReaction {
+	reaction := &MessageReaction{
+		ReactionId: getInMap(data, "reaction_id", "").(string),
+		ActionTime: millisTime(getInMap(data, "action_time", "").(string)),
+	}
+	if operator, ok := data["operator"].(map[string]any); ok {
+		reaction.OperatorId = getInMap(operator, "operator_id", "").(string)
+		reaction.OperatorType = getInMap(operator, "operator_type", "").(string)
+	}
+	if reactionType, ok := data["reaction_type"].(map[string]any); ok {
+		reaction.EmojiType = getInMap(reactionType, "emoji_type", "").(string)
+	}
+	return reaction
+}
+
+// Add an emoji reaction to a message
+func (c AppClient) MessageReactionAdd(messageId string, emojiType string) *MessageReaction {
+	result, err := c.MessageReactionAddWithError(messageId, emojiType)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message reaction add error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageReactionAddWithError(messageId string, emojiType string) (*MessageReaction, error) {
+	return c.MessageReactionAddWithContext(context.Background(), messageId, emojiType)
+}
+
+func (c AppClient) MessageReactionAddWithContext(ctx context.Context, messageId string, emojiType string) (*MessageReaction, error) {
+	body := make(map[string]any)
+	body["reaction_type"] = map[string]string{"emoji_type": emojiType}
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/messages/"+messageId+"/reactions", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	return NewMessageReaction(resp), nil
+}
+
+// Remove a reaction which is added by the bot
+func (c AppClient) MessageReactionDelete(messageId string, reactionId string) {
+	if err := c.MessageReactionDeleteWithError(messageId, reactionId); err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message reaction delete error")
+	}
+}
+
+func (c AppClient) MessageReactionDeleteWithError(messageId string, reactionId string) error {
+	return c.MessageReactionDeleteWithContext(context.Background(), messageId, reactionId)
+}
+
+func (c AppClient) MessageReactionDeleteWithContext(ctx context.Context, messageId string, reactionId string) error {
+	_, err := c.RequestWithContext(ctx, "delete", "open-apis/im/v1/messages/"+messageId+"/reactions/"+reactionId, nil, nil, nil)
+	return err
+}
+
+// List the reactions of a message, only the reactions of emojiType are listed if it isn't empty
+func (c AppClient) MessageReactionList(messageId string, emojiType string) []MessageReaction {
+	result, err := c.MessageReactionListWithError(messageId, emojiType)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message reaction list error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageReactionListWithError(messageId string, emojiType string) ([]MessageReaction, error) {
+	return c.MessageReactionListWithContext(context.Background(), messageId, emojiType)
+}
+
+func (c AppClient) MessageReactionListWithContext(ctx context.Context, messageId string, emojiType string) ([]MessageReaction, error) {
+	query := make(map[string]any)
+	if emojiType != "" {
+		query["reaction_type"] = emojiType
+	}
+
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/im/v1/messages/"+messageId+"/reactions", query, nil, nil, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions := make([]MessageReaction, 0, len(l))
+	for _, value := range l {
+		data, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		reactions = append(reactions, *NewMessageReaction(data))
+	}
+	return reactions, nil
+}
+
+type MessagePin struct {
+	MessageId  string
+	ChatId     string
+	OperatorId string
+	// "user" for open_id, "app" for app_id
+	OperatorIdType string
+	CreateTime     time.Time
+}
+
+// Create a new MessagePin
+func NewMessagePin(data map[string]any) *MessagePin {
+	return &MessagePin{
+		MessageId:      getInMap(data, "message_id", "").(string),
+		ChatId:         getInMap(data, "chat_id", "").(string),
+		OperatorId:     getInMap(data, "operator_id", "").(string),
+		OperatorIdType: getInMap(data, "operator_id_type", "").(string),
+		CreateTime:     millisTime(getInMap(data, "create_time", "").(string)),
+	}
+}
+
+// Pin a message in its chat group
+func (c AppClient) MessagePin(messageId string) *MessagePin {
+	result, err := c.MessagePinWithError(messageId)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message pin error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessagePinWithError(messageId string) (*MessagePin, error) {
+	return c.MessagePinWithContext(context.Background(), messageId)
+}
+
+func (c AppClient) MessagePinWithContext(ctx context.Context, messageId string) (*MessagePin, error) {
+	body := make(map[string]string)
+	body["message_id"] = messageId
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/pins", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	pin, ok := resp["pin"].(map[string]any)
+	if !ok {
+		return nil, errors.New("pin is missing in response")
+	}
+	return NewMessagePin(pin), nil
+}
+
+// Unpin a message
+func (c AppClient) MessageUnpin(messageId string) {
+	if err := c.MessageUnpinWithError(messageId); err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message unpin error")
+	}
+}
+
+func (c AppClient) MessageUnpinWithError(messageId string) error {
+	return c.MessageUnpinWithContext(context.Background(), messageId)
+}
+
+func (c AppClient) MessageUnpinWithContext(ctx context.Context, messageId string) error {
+	_, err := c.RequestWithContext(ctx, "delete", "open-apis/im/v1/pins/"+messageId, nil, nil, nil)
+	return err
+}
+
+// List the pinned messages of a chat group which are pinned between start and end, a zero start or end leaves that side open
+func (c AppClient) MessagePinList(chatId string, start time.Time, end time.Time) []MessagePin {
+	result, err := c.MessagePinListWithError(chatId, start, end)
+	if err != nil {
+		logrus.WithField("ChatID", chatId).WithError(err).Error("message pin list error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessagePinListWithError(chatId string, start time.Time, end time.Time) ([]MessagePin, error) {
+	return c.MessagePinListWithContext(context.Background(), chatId, start, end)
+}
+
+func (c AppClient) MessagePinListWithContext(ctx context.Context, chatId string, start time.Time, end time.Time) ([]MessagePin, error) {
+	query := make(map[string]any)
+	query["chat_id"] = chatId
+	if !start.IsZero() {
+		query["start_time"] = strconv.FormatInt(start.UnixMilli(), 10)
+	}
+	if !end.IsZero() {
+		query["end_time"] = strconv.FormatInt(end.UnixMilli(), 10)
+	}
+
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/im/v1/pins", query, nil, nil, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := make([]MessagePin, 0, len(l))
+	for _, value := range l {
+		data, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		pins = append(pins, *NewMessagePin(data))
+	}
+	return pins, nil
+}
@@ -0,0 +1,48 @@
+package feishuapi
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type UrgentType string
+
+const (
+	// buzz in the Feishu app
+	UrgentApp UrgentType = "urgent_app"
+	// buzz by a text message to the phone of the user
+	UrgentSms UrgentType = "urgent_sms"
+	// buzz by a phone call
+	UrgentPhone UrgentType = "urgent_phone"
+)
+
+// Buzz the users about a message sent by the bot, the users must be able to see the message.
+// Return the ids of the users which can't be buzzed
+func (c AppClient) MessageUrgent(messageId string, urgentType UrgentType, userIdType UserIdType, userIds []string) []string {
+	result, err := c.MessageUrgentWithError(messageId, urgentType, userIdType, userIds)
+	if err != nil {
+		logrus.WithField("MessageID", messageId).WithError(err).Error("message urgent error")
+		return nil
+	}
+	return result
+}
+
+func (c AppClient) MessageUrgentWithError(messageId string, urgentType UrgentType, userIdType UserIdType, userIds []string) ([]string, error) {
+	return c.MessageUrgentWithContext(context.Background(), messageId, urgentType, userIdType, userIds)
+}
+
+func (c AppClient) MessageUrgentWithContext(ctx context.Context, messageId string, urgentType UrgentType, userIdType UserIdType, userIds []string) ([]string, error) {
+	query := make(map[string]any)
+	query["user_id_type"] = string(userIdType)
+
+	body := make(map[string]any)
+	body["user_id_list"] = userIds
+
+	resp, err := c.RequestWithContext(ctx, "patch", "open-apis/im/v1/messages/"+messageId+"/"+string(urgentType), query, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return getStringsInMap(resp, "invalid_user_id_list"), nil
+}
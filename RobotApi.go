@@ -1,11 +1,8 @@
 package feishuapi
 
 import (
-	"encoding/json"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"time"
+	"context"
+	"errors"
 
 	"github.com/sirupsen/logrus"
 )
@@ -23,75 +20,27 @@ func NewRobotInfo(data map[string]any) *RobotInfo {
 }
 
 func (c AppClient) RobotGetInfo() *RobotInfo {
-	u := "https://open.feishu.cn/open-apis/bot/v3/info"
-
-	header := make(map[string]string)
-	header["Authorization"] = "Bearer " + c._tenant_access_token
-
-	var req *http.Request
-
-	Url, err := url.Parse(u)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"url": u,
-			"err": err,
-		}).Error("url parse error")
-		return nil
-	}
-
-	urlPath := Url.String()
-
-	req, err = http.NewRequest("GET", urlPath, nil)
+	info, err := c.RobotGetInfoWithError()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"url": u,
-			"err": err,
-		}).Error("Request create error")
+		logrus.WithError(err).Error("get robot info fail")
 		return nil
 	}
+	return info
+}
 
-	for k, v := range header {
-		req.Header.Add(k, v)
-	}
-
-	cli := &http.Client{
-		Timeout: time.Second * 15,
-	}
+func (c AppClient) RobotGetInfoWithError() (*RobotInfo, error) {
+	return c.RobotGetInfoWithContext(context.Background())
+}
 
-	resp, err := cli.Do(req)
+func (c AppClient) RobotGetInfoWithContext(ctx context.Context) (*RobotInfo, error) {
+	result, err := c.requestEnvelope(ctx, "get", "open-apis/bot/v3/info", nil, nil, nil)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"url": u,
-			"err": err,
-		}).Error("client do error")
-		return nil
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if !responseOk(resp) {
-		respBody, _ := ioutil.ReadAll(resp.Body)
-		logrus.WithFields(logrus.Fields{
-			"url":      u,
-			"headers":  header,
-			"error":    "response incorrect",
-			"response": string(respBody),
-		}).Error("response status error")
-		return nil
+	bot, ok := result["bot"].(map[string]any)
+	if !ok {
+		return nil, errors.New("bot info is missing in response")
 	}
-
-	respBody, err := ioutil.ReadAll(resp.Body)
-
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"url": u,
-			"err": err,
-		}).Error("read response body error")
-		return nil
-	}
-
-	var result map[string]any
-
-	json.Unmarshal(respBody, &result)
-
-	return NewRobotInfo(result["bot"].(map[string]any))
+	return NewRobotInfo(bot), nil
 }
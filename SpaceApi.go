@@ -1,6 +1,11 @@
 package feishuapi
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
 
 type SpaceInfo struct {
 	Name        string
@@ -19,66 +24,109 @@ func NewSpaceInfo(data map[string]any) *SpaceInfo {
 
 // Create a Knowledge Space
 func (c AppClient) KnowledgeSpaceCreate(name string, description string, user_access_token string) *SpaceInfo {
-	body := make(map[string]string)
-	body["name"] = name
-	body["description"] = description
-
-	headers := make(map[string]string)
-	headers["Authorization"] = user_access_token
-
-	info := c.Request("post", "open-apis/wiki/v2/spaces", nil, headers, body)
-
-	if info == nil {
+	info, err := c.KnowledgeSpaceCreateWithError(name, description, user_access_token)
+	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"Name":        name,
 			"Description": description,
+			"error":       err,
 		}).Error("create knowledge space fail")
 		return nil
 	}
+	return info
+}
 
-	return NewSpaceInfo(info["space"].(map[string]any))
+func (c AppClient) KnowledgeSpaceCreateWithError(name string, description string, user_access_token string) (*SpaceInfo, error) {
+	return c.KnowledgeSpaceCreateWithContext(context.Background(), name, description, StaticUserToken(user_access_token))
+}
+
+func (c AppClient) KnowledgeSpaceCreateWithContext(ctx context.Context, name string, description string, userToken UserToken) (*SpaceInfo, error) {
+	body := make(map[string]string)
+	body["name"] = name
+	body["description"] = description
+
+	headers, err := userAuthHeaders(ctx, userToken)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := c.RequestWithContext(ctx, "post", "open-apis/wiki/v2/spaces", nil, headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	space, ok := info["space"].(map[string]any)
+	if !ok {
+		return nil, errors.New("space is missing in response")
+	}
+	return NewSpaceInfo(space), nil
 }
 
 // Add members to a Knowledge Space
 // memberType: "openchat" for chat id, "userid" for feishuapi.UserId, "unionid" for feishuapi.UnionId, "opendepartmentid" for DepartmentId
 func (c AppClient) KnowledgeSpaceAddMembers(spaceId string, membersId []string, memberType string) {
-	body := make(map[string]string)
-	body["member_type"] = memberType
-	body["member_role"] = "member"
-	for _, v := range membersId {
-		body["member_id"] = v
-		resp := c.Request("post", "open-apis/wiki/v2/spaces/"+spaceId+"/members", nil, nil, body)
-
-		if resp == nil {
-			logrus.WithFields(logrus.Fields{
-				"SpaceID":    spaceId,
-				"MemberType": memberType,
-				"MemberID":   v,
-			}).Warn("add member fail")
-		}
-	}
+	c.knowledgeSpaceAddMembers(context.Background(), spaceId, membersId, memberType, "member", nil, func(memberId string, err error) {
+		logrus.WithFields(logrus.Fields{
+			"SpaceID":    spaceId,
+			"MemberType": memberType,
+			"MemberID":   memberId,
+			"error":      err,
+		}).Warn("add member fail")
+	})
+}
+
+func (c AppClient) KnowledgeSpaceAddMembersWithError(spaceId string, membersId []string, memberType string) error {
+	return c.KnowledgeSpaceAddMembersWithContext(context.Background(), spaceId, membersId, memberType)
+}
+
+func (c AppClient) KnowledgeSpaceAddMembersWithContext(ctx context.Context, spaceId string, membersId []string, memberType string) error {
+	return c.knowledgeSpaceAddMembers(ctx, spaceId, membersId, memberType, "member", nil, nil)
 }
 
 // Add robots to a Knowledge Space as admin
 func (c AppClient) KnowledgeSpaceAddBotsAsAdmin(spaceId string, BotsId []string, user_access_token string) {
-	headers := make(map[string]string)
-	headers["Authorization"] = user_access_token
+	headers, _ := userAuthHeaders(context.Background(), StaticUserToken(user_access_token))
 
+	c.knowledgeSpaceAddMembers(context.Background(), spaceId, BotsId, "openid", "admin", headers, func(memberId string, err error) {
+		logrus.WithFields(logrus.Fields{
+			"SpaceID":  spaceId,
+			"MemberID": memberId,
+			"error":    err,
+		}).Warn("add bot fail")
+	})
+}
+
+func (c AppClient) KnowledgeSpaceAddBotsAsAdminWithError(spaceId string, BotsId []string, user_access_token string) error {
+	return c.KnowledgeSpaceAddBotsAsAdminWithContext(context.Background(), spaceId, BotsId, StaticUserToken(user_access_token))
+}
+
+func (c AppClient) KnowledgeSpaceAddBotsAsAdminWithContext(ctx context.Context, spaceId string, BotsId []string, userToken UserToken) error {
+	headers, err := userAuthHeaders(ctx, userToken)
+	if err != nil {
+		return err
+	}
+
+	return c.knowledgeSpaceAddMembers(ctx, spaceId, BotsId, "openid", "admin", headers, nil)
+}
+
+// Add the members one by one, onFail is called for every member which can't be added
+func (c AppClient) knowledgeSpaceAddMembers(ctx context.Context, spaceId string, membersId []string, memberType string, memberRole string, headers map[string]string, onFail func(memberId string, err error)) error {
 	body := make(map[string]string)
-	body["member_type"] = "openid"
-	body["member_role"] = "admin"
+	body["member_type"] = memberType
+	body["member_role"] = memberRole
 
-	for _, v := range BotsId {
+	var errs []error
+	for _, v := range membersId {
 		body["member_id"] = v
-		resp := c.Request("post", "open-apis/wiki/v2/spaces/"+spaceId+"/members", nil, headers, body)
-
-		if resp == nil {
-			logrus.WithFields(logrus.Fields{
-				"SpaceID":  spaceId,
-				"MemberID": v,
-			}).Warn("add bot fail")
+		_, err := c.RequestWithContext(ctx, "post", "open-apis/wiki/v2/spaces/"+spaceId+"/members", nil, headers, body)
+		if err != nil {
+			if onFail != nil {
+				onFail(v, err)
+			}
+			errs = append(errs, err)
 		}
 	}
+	return joinErrors(errs)
 }
 
 type NodeInfo struct {
@@ -103,64 +151,104 @@ func NewNodeInfo(data map[string]any) *NodeInfo {
 }
 
 func (c AppClient) KnowledgeSpaceGetNodeInfo(NodeToken string) *NodeInfo {
+	node, err := c.KnowledgeSpaceGetNodeInfoWithError(NodeToken)
+	if err != nil {
+		logrus.WithField("NodeToken", NodeToken).WithError(err).Error("get node info fail")
+		return nil
+	}
+	return node
+}
+
+func (c AppClient) KnowledgeSpaceGetNodeInfoWithError(NodeToken string) (*NodeInfo, error) {
+	return c.KnowledgeSpaceGetNodeInfoWithContext(context.Background(), NodeToken)
+}
+
+func (c AppClient) KnowledgeSpaceGetNodeInfoWithContext(ctx context.Context, NodeToken string) (*NodeInfo, error) {
 	query := make(map[string]any)
 	query["token"] = NodeToken
 
-	info := c.Request("get", "open-apis/wiki/v2/spaces/get_node", query, nil, nil)
-
-	if info == nil {
-		logrus.WithField("NodeToken", NodeToken).Error("copy node fail")
-		return nil
+	info, err := c.RequestWithContext(ctx, "get", "open-apis/wiki/v2/spaces/get_node", query, nil, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return NewNodeInfo(info["node"].(map[string]any))
+	return newNodeInfoFromResponse(info)
 }
 
 // Copy a node from SpaceId/NodeToken to TargetSpaceId/TargetParentToken
 func (c AppClient) KnowledgeSpaceCopyNode(SpaceId string, NodeToken string, TargetSpaceId string, TargetParentToken string, Title ...string) *NodeInfo {
-	body := make(map[string]string)
-	body["target_parent_token"] = TargetParentToken
-	body["target_space_id"] = TargetSpaceId
-	if len(Title) != 0 {
-		body["title"] = Title[0]
-	}
-
-	info := c.Request("post", "open-apis/wiki/v2/spaces/"+SpaceId+"/nodes/"+NodeToken+"/copy", nil, nil, body)
-
-	if info == nil {
+	node, err := c.KnowledgeSpaceCopyNodeWithError(SpaceId, NodeToken, TargetSpaceId, TargetParentToken, Title...)
+	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"SpaceID":           SpaceId,
 			"NodeToken":         NodeToken,
 			"TargetSpaceID":     TargetSpaceId,
 			"TargetParentToken": TargetParentToken,
+			"error":             err,
 		}).Error("copy node fail")
 		return nil
 	}
+	return node
+}
 
-	return NewNodeInfo(info["node"].(map[string]any))
+func (c AppClient) KnowledgeSpaceCopyNodeWithError(SpaceId string, NodeToken string, TargetSpaceId string, TargetParentToken string, Title ...string) (*NodeInfo, error) {
+	return c.KnowledgeSpaceCopyNodeWithContext(context.Background(), SpaceId, NodeToken, TargetSpaceId, TargetParentToken, Title...)
+}
+
+func (c AppClient) KnowledgeSpaceCopyNodeWithContext(ctx context.Context, SpaceId string, NodeToken string, TargetSpaceId string, TargetParentToken string, Title ...string) (*NodeInfo, error) {
+	body := make(map[string]string)
+	body["target_parent_token"] = TargetParentToken
+	body["target_space_id"] = TargetSpaceId
+	if len(Title) != 0 {
+		body["title"] = Title[0]
+	}
+
+	info, err := c.RequestWithContext(ctx, "post", "open-apis/wiki/v2/spaces/"+SpaceId+"/nodes/"+NodeToken+"/copy", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNodeInfoFromResponse(info)
+}
+
+func newNodeInfoFromResponse(info map[string]any) (*NodeInfo, error) {
+	node, ok := info["node"].(map[string]any)
+	if !ok {
+		return nil, errors.New("node is missing in response")
+	}
+	return NewNodeInfo(node), nil
 }
 
 // Get All Nodes in target Space and under specific ParentNode(not necessary)
 func (c AppClient) KnowledgeSpaceGetAllNodes(SpaceId string, ParentNodeToken ...string) []NodeInfo {
+	all_node, err := c.KnowledgeSpaceGetAllNodesWithError(SpaceId, ParentNodeToken...)
+	if err != nil {
+		logrus.WithField("SpaceID", SpaceId).WithError(err).Error("nil node info return")
+		return nil
+	}
+	return all_node
+}
+
+func (c AppClient) KnowledgeSpaceGetAllNodesWithError(SpaceId string, ParentNodeToken ...string) ([]NodeInfo, error) {
+	return c.KnowledgeSpaceGetAllNodesWithContext(context.Background(), SpaceId, ParentNodeToken...)
+}
+
+func (c AppClient) KnowledgeSpaceGetAllNodesWithContext(ctx context.Context, SpaceId string, ParentNodeToken ...string) ([]NodeInfo, error) {
 	var all_node []NodeInfo
-	var l []any
 
+	query := make(map[string]any)
 	if len(ParentNodeToken) != 0 {
-		query := make(map[string]any)
 		query["parent_node_token"] = ParentNodeToken[0]
-		l = c.GetAllPages("get", "open-apis/wiki/v2/spaces/"+SpaceId+"/nodes", query, nil, nil, 10)
-	} else {
-		l = c.GetAllPages("get", "open-apis/wiki/v2/spaces/"+SpaceId+"/nodes", nil, nil, nil, 10)
 	}
 
-	if l == nil {
-		logrus.WithField("SpaceID", SpaceId).Error("nil node info return")
-		return nil
+	l, err := c.GetAllPagesWithContext(ctx, "get", "open-apis/wiki/v2/spaces/"+SpaceId+"/nodes", query, nil, nil, 10)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, value := range l {
 		all_node = append(all_node, *NewNodeInfo(value.(map[string]any)))
 	}
 
-	return all_node
+	return all_node, nil
 }
@@ -1,6 +1,10 @@
 package feishuapi
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
 
 type FileStatistics struct {
 	// 文档历史访问人数，同一用户（user_id）多次访问按一次计算。
@@ -27,18 +31,30 @@ func (c AppClient) NewStatistics(data map[string]any) *FileStatistics {
 
 // StatisticsGetAllInfo Get the statistics of a file
 func (c AppClient) StatisticsGetAllInfo(fileToken, fileType string) *FileStatistics {
-	query := make(map[string]any)
-	query["file_type"] = fileType
-
-	responseMap := c.Request("get", "open-apis/drive/v1/files/"+fileToken+"/statistics", query, nil, nil)
-
-	if responseMap == nil {
+	statistics, err := c.StatisticsGetAllInfoWithError(fileToken, fileType)
+	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"FileToken": fileToken,
 			"FileType":  fileType,
+			"error":     err,
 		}).Warn("nil responseMap return")
 		return nil
 	}
+	return statistics
+}
+
+func (c AppClient) StatisticsGetAllInfoWithError(fileToken, fileType string) (*FileStatistics, error) {
+	return c.StatisticsGetAllInfoWithContext(context.Background(), fileToken, fileType)
+}
+
+func (c AppClient) StatisticsGetAllInfoWithContext(ctx context.Context, fileToken, fileType string) (*FileStatistics, error) {
+	query := make(map[string]any)
+	query["file_type"] = fileType
+
+	responseMap, err := c.RequestWithContext(ctx, "get", "open-apis/drive/v1/files/"+fileToken+"/statistics", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.NewStatistics(responseMap)
+	return c.NewStatistics(responseMap), nil
 }
@@ -1,6 +1,15 @@
 package feishuapi
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
 
 type LoginSession struct {
 	OpenId     string
@@ -8,63 +17,143 @@ type LoginSession struct {
 }
 
 type UserAccessToken struct {
-	Access_token  string
-	Name          string
-	Refresh_token string
-	User_id       string
-	Open_id       string
+	Access_token       string
+	Name               string
+	Refresh_token      string
+	User_id            string
+	Open_id            string
+	Expires_in         int
+	Refresh_expires_in int
+	// the time when Access_token / Refresh_token expires, computed from Expires_in / Refresh_expires_in
+	ExpireAt        time.Time
+	RefreshExpireAt time.Time
 }
 
 // Create a new LoginSession
 func NewLoginSession(data map[string]any) *LoginSession {
 	return &LoginSession{
-		OpenId:     data["open_id"].(string),
-		EmployeeId: data["employee_id"].(string),
+		OpenId:     getInMap(data, "open_id", "").(string),
+		EmployeeId: getInMap(data, "employee_id", "").(string),
 	}
 }
 
 // Create a new UserAccessToken
 func NewUserAccessToken(data map[string]any) *UserAccessToken {
+	now := time.Now()
+	expiresIn := int(getInMap(data, "expires_in", float64(0)).(float64))
+	refreshExpiresIn := int(getInMap(data, "refresh_expires_in", float64(0)).(float64))
 	return &UserAccessToken{
-		Access_token:  data["access_token"].(string),
-		Name:          data["name"].(string),
-		Refresh_token: data["refresh_token"].(string),
-		User_id:       getInMap(data, "user_id", "").(string),
-		Open_id:       data["open_id"].(string),
+		Access_token:       getInMap(data, "access_token", "").(string),
+		Name:               getInMap(data, "name", "").(string),
+		Refresh_token:      getInMap(data, "refresh_token", "").(string),
+		User_id:            getInMap(data, "user_id", "").(string),
+		Open_id:            getInMap(data, "open_id", "").(string),
+		Expires_in:         expiresIn,
+		Refresh_expires_in: refreshExpiresIn,
+		ExpireAt:           now.Add(time.Duration(expiresIn) * time.Second),
+		RefreshExpireAt:    now.Add(time.Duration(refreshExpiresIn) * time.Second),
+	}
+}
+
+// Parse the UserAccessToken in a response, the access_token and refresh_token are required
+func parseUserAccessToken(data map[string]any) (*UserAccessToken, error) {
+	token := NewUserAccessToken(data)
+	if token.Access_token == "" {
+		return nil, errors.New("access_token is missing in response")
+	}
+	if token.Refresh_token == "" {
+		return nil, errors.New("refresh_token is missing in response")
 	}
+	return token, nil
 }
 
 // Get the login session by login_token
 func (c AppClient) GetLoginSession(login_token string) *LoginSession {
+	session, err := c.GetLoginSessionWithError(login_token)
+	if err != nil {
+		logrus.WithError(err).Error("nil login session return")
+		return nil
+	}
+	return session
+}
+
+func (c AppClient) GetLoginSessionWithError(login_token string) (*LoginSession, error) {
+	return c.GetLoginSessionWithContext(context.Background(), login_token)
+}
+
+func (c AppClient) GetLoginSessionWithContext(ctx context.Context, login_token string) (*LoginSession, error) {
 	body := make(map[string]string)
 	body["code"] = login_token
 
-	resp := c.Request("post", "open-apis/mina/v2/tokenLoginValidate", nil, nil, body)
-	if resp == nil {
-		logrus.Error("nil login session return")
-		return nil
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/mina/v2/tokenLoginValidate", nil, nil, body)
+	if err != nil {
+		return nil, err
 	}
-	return NewLoginSession(resp)
+	session := NewLoginSession(resp)
+	if session.OpenId == "" {
+		return nil, errors.New("open_id is missing in response")
+	}
+	return session, nil
 }
 
 // Get the UserAccessToken
 func (c *AppClient) GetUserAccessToken(code string) *UserAccessToken {
+	token, err := c.GetUserAccessTokenWithError(code)
+	if err != nil {
+		logrus.WithError(err).Error("nil user access token return")
+		return nil
+	}
+	return token
+}
+
+func (c *AppClient) GetUserAccessTokenWithError(code string) (*UserAccessToken, error) {
+	return c.GetUserAccessTokenWithContext(context.Background(), code)
+}
+
+func (c *AppClient) GetUserAccessTokenWithContext(ctx context.Context, code string) (*UserAccessToken, error) {
 	u := "open-apis/authen/v1/access_token"
 
 	body := make(map[string]string)
 	body["grant_type"] = "authorization_code"
 	body["code"] = code
 
-	resp := c.Request("post", u, nil, nil, body)
+	resp, err := c.RequestWithContext(ctx, "post", u, nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
 
-	if resp == nil {
-		logrus.Error("nil user access token return")
+	return parseUserAccessToken(resp)
+}
+
+// Get a new UserAccessToken by the refresh_token of an old one
+func (c AppClient) RefreshUserAccessToken(refreshToken string) *UserAccessToken {
+	token, err := c.RefreshUserAccessTokenWithError(refreshToken)
+	if err != nil {
+		logrus.WithError(err).Error("nil user access token return")
 		return nil
 	}
+	return token
+}
+
+func (c AppClient) RefreshUserAccessTokenWithError(refreshToken string) (*UserAccessToken, error) {
+	return c.RefreshUserAccessTokenWithContext(context.Background(), refreshToken)
+}
+
+func (c AppClient) RefreshUserAccessTokenWithContext(ctx context.Context, refreshToken string) (*UserAccessToken, error) {
+	body := make(map[string]string)
+	body["grant_type"] = "refresh_token"
+	body["refresh_token"] = refreshToken
+
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/authen/v1/refresh_access_token", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
 
-	return NewUserAccessToken(resp)
+	return parseUserAccessToken(resp)
 }
 
+// Deprecated: authen/v1/index is a page which the browser is redirected to, it can't return the code to a server.
+// Use AuthorizeURL, or LoginHandler and LoginCallbackHandler instead
 func (c *AppClient) GetCode(redirectURL string, appID string) string {
 	u := "open-apis/authen/v1/index"
 
@@ -79,5 +168,177 @@ func (c *AppClient) GetCode(redirectURL string, appID string) string {
 		return ""
 	}
 
-	return resp["code"].(string)
+	code, _ := resp["code"].(string)
+	return code
+}
+
+// UserToken supplies the user_access_token for the user-scoped apis, such as CalendarCreateByUserWithContext
+type UserToken interface {
+	UserAccessToken(ctx context.Context) (string, error)
+}
+
+// StaticUserToken is a raw user_access_token, with or without the "Bearer " prefix
+type StaticUserToken string
+
+func (t StaticUserToken) UserAccessToken(ctx context.Context) (string, error) {
+	return strings.TrimPrefix(string(t), "Bearer "), nil
+}
+
+// Build the Authorization header for a user-scoped api
+func userAuthHeaders(ctx context.Context, userToken UserToken) (map[string]string, error) {
+	if userToken == nil {
+		return nil, errors.New("user token is required")
+	}
+	token, err := userToken.UserAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	headers["Authorization"] = "Bearer " + token
+	return headers, nil
+}
+
+const userTokenRefreshAhead = time.Minute * 5
+
+// UserTokenSource is a UserToken which refreshes the user_access_token of one user before it expires.
+// If a store is given, the token is persisted in it under the open_id of the user,
+// so that it survives restarts and can be shared by several processes
+type UserTokenSource struct {
+	client AppClient
+	store  TokenStore
+	openId string
+
+	mu    sync.Mutex
+	token *UserAccessToken
+}
+
+// Create a UserTokenSource from the token got by GetUserAccessToken, the token is saved to store if it isn't nil
+func (c AppClient) NewUserTokenSource(ctx context.Context, token *UserAccessToken, store TokenStore) (*UserTokenSource, error) {
+	if token == nil {
+		return nil, errors.New("user access token is required")
+	}
+	source := &UserTokenSource{
+		client: c,
+		store:  store,
+		openId: token.Open_id,
+		token:  token,
+	}
+	if store != nil {
+		value, err := json.Marshal(token)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.Set(ctx, userTokenStoreKey(token.Open_id), string(value), time.Until(token.RefreshExpireAt)); err != nil {
+			return nil, err
+		}
+	}
+	return source, nil
+}
+
+// Load the UserTokenSource of the user from store, ErrTokenNotFound if the user has no unexpired token in it
+func (c AppClient) LoadUserTokenSource(ctx context.Context, openId string, store TokenStore) (*UserTokenSource, error) {
+	source := &UserTokenSource{
+		client: c,
+		store:  store,
+		openId: openId,
+	}
+	token, _, err := source.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	source.token = token
+	return source, nil
+}
+
+func (s *UserTokenSource) OpenId() string {
+	return s.openId
+}
+
+func (s *UserTokenSource) UserAccessToken(ctx context.Context) (string, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.Access_token, nil
+}
+
+// Get the current token of the user, refresh it if it is going to expire
+func (s *UserTokenSource) Token(ctx context.Context) (*UserAccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && time.Until(s.token.ExpireAt) > userTokenRefreshAhead {
+		return s.token, nil
+	}
+
+	// another process may have refreshed the token already
+	old := ""
+	if s.store != nil {
+		token, value, err := s.load(ctx)
+		if err != nil && !errors.Is(err, ErrTokenNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			if time.Until(token.ExpireAt) > userTokenRefreshAhead {
+				s.token = token
+				return token, nil
+			}
+			s.token = token
+			old = value
+		}
+	}
+
+	if s.token == nil || !time.Now().Before(s.token.RefreshExpireAt) {
+		return nil, errors.New("refresh_token of user " + s.openId + " has expired, the user needs to login again")
+	}
+
+	token, err := s.client.RefreshUserAccessTokenWithContext(ctx, s.token.Refresh_token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Open_id == "" {
+		token.Open_id = s.openId
+	}
+
+	if s.store != nil {
+		value, err := json.Marshal(token)
+		if err != nil {
+			return nil, err
+		}
+		swapped, err := s.store.CompareAndSwap(ctx, userTokenStoreKey(s.openId), old, string(value), time.Until(token.RefreshExpireAt))
+		if err != nil {
+			return nil, err
+		}
+		if !swapped {
+			// the refresh_token can only be used once, take the token which is refreshed by the other process
+			stored, _, err := s.load(ctx)
+			if err != nil {
+				return nil, err
+			}
+			token = stored
+		}
+	}
+
+	s.token = token
+	return token, nil
+}
+
+func (s *UserTokenSource) load(ctx context.Context) (*UserAccessToken, string, error) {
+	if s.store == nil {
+		return nil, "", ErrTokenNotFound
+	}
+	value, _, err := s.store.Get(ctx, userTokenStoreKey(s.openId))
+	if err != nil {
+		return nil, "", err
+	}
+	token := &UserAccessToken{}
+	if err := json.Unmarshal([]byte(value), token); err != nil {
+		return nil, "", err
+	}
+	return token, value, nil
+}
+
+func userTokenStoreKey(openId string) string {
+	return "feishuapi:user_access_token:" + openId
 }
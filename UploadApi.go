@@ -0,0 +1,149 @@
+package feishuapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// multipartBody is a multipart/form-data request body with one file.
+// The file is read into memory, so that the request can be retried
+type multipartBody struct {
+	fields    map[string]string
+	fileField string
+	fileName  string
+	file      []byte
+}
+
+func newMultipartBody(fields map[string]string, fileField string, fileName string, file io.Reader) (*multipartBody, error) {
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return &multipartBody{
+		fields:    fields,
+		fileField: fileField,
+		fileName:  fileName,
+		file:      data,
+	}, nil
+}
+
+func (b *multipartBody) encode() ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	keys := make([]string, 0, len(b.fields))
+	for k := range b.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := w.WriteField(k, b.fields[k]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	part, err := w.CreateFormFile(b.fileField, b.fileName)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(b.file); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// Upload an image which is used in the messages and message cards, return the image_key.
+// The image should be no larger than 10MB
+func (c AppClient) ImageUpload(image io.Reader) string {
+	result, err := c.ImageUploadWithError(image)
+	if err != nil {
+		logrus.WithError(err).Error("image upload error")
+		return ""
+	}
+	return result
+}
+
+func (c AppClient) ImageUploadWithError(image io.Reader) (string, error) {
+	return c.ImageUploadWithContext(context.Background(), image)
+}
+
+func (c AppClient) ImageUploadWithContext(ctx context.Context, image io.Reader) (string, error) {
+	fields := make(map[string]string)
+	fields["image_type"] = "message"
+
+	body, err := newMultipartBody(fields, "image", "image", image)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/images", nil, nil, body)
+	if err != nil {
+		return "", err
+	}
+
+	imageKey, _ := resp["image_key"].(string)
+	if imageKey == "" {
+		return "", errors.New("image_key is missing in response")
+	}
+	return imageKey, nil
+}
+
+type UploadFileType string
+
+const (
+	// the audio must be in opus format to be sent as an Audio message
+	UploadFileOpus UploadFileType = "opus"
+	// the video must be in mp4 format to be sent as a Media message
+	UploadFileMp4 UploadFileType = "mp4"
+	UploadFilePdf UploadFileType = "pdf"
+	UploadFileDoc UploadFileType = "doc"
+	UploadFileXls UploadFileType = "xls"
+	UploadFilePpt UploadFileType = "ppt"
+	// the other types
+	UploadFileStream UploadFileType = "stream"
+)
+
+// Upload a file which is sent as a File, Audio or Media message, return the file_key.
+// The file should be no larger than 30MB
+func (c AppClient) FileUpload(name string, fileType UploadFileType, file io.Reader) string {
+	result, err := c.FileUploadWithError(name, fileType, file)
+	if err != nil {
+		logrus.WithField("Name", name).WithError(err).Error("file upload error")
+		return ""
+	}
+	return result
+}
+
+func (c AppClient) FileUploadWithError(name string, fileType UploadFileType, file io.Reader) (string, error) {
+	return c.FileUploadWithContext(context.Background(), name, fileType, file)
+}
+
+func (c AppClient) FileUploadWithContext(ctx context.Context, name string, fileType UploadFileType, file io.Reader) (string, error) {
+	fields := make(map[string]string)
+	fields["file_type"] = string(fileType)
+	fields["file_name"] = name
+
+	body, err := newMultipartBody(fields, "file", name, file)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.RequestWithContext(ctx, "post", "open-apis/im/v1/files", nil, nil, body)
+	if err != nil {
+		return "", err
+	}
+
+	fileKey, _ := resp["file_key"].(string)
+	if fileKey == "" {
+		return "", errors.New("file_key is missing in response")
+	}
+	return fileKey, nil
+}
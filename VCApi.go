@@ -1,6 +1,7 @@
 package feishuapi
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/sirupsen/logrus"
 	"strconv"
@@ -91,86 +92,80 @@ func NewVCReserve(data map[string]any) *VCReserve {
 }
 
 func (c AppClient) VCReserve(reserveRequest *VCReserveRequest) *VCReserve {
+	reserve, err := c.VCReserveWithError(reserveRequest)
+	if err != nil {
+		logrus.WithError(err).Error("reserve meeting fail")
+		return nil
+	}
+	return reserve
+}
+
+func (c AppClient) VCReserveWithError(reserveRequest *VCReserveRequest) (*VCReserve, error) {
+	return c.VCReserveWithContext(context.Background(), reserveRequest)
+}
+
+func (c AppClient) VCReserveWithContext(ctx context.Context, reserveRequest *VCReserveRequest) (*VCReserve, error) {
 	body := make(map[string]any)
-	struct2map(reserveRequest, &body)
+	if err := struct2map(reserveRequest, &body); err != nil {
+		return nil, err
+	}
 
-	info := c.Request("post", "/open-apis/vc/v1/reserves/apply", nil, nil, body)
-	return NewVCReserve(info)
+	info, err := c.RequestWithContext(ctx, "post", "/open-apis/vc/v1/reserves/apply", nil, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	return NewVCReserve(info), nil
 }
 
 func (c AppClient) VCReserveWithTopic(topic string, endTime time.Time) *VCReserve {
 	return c.VCReserve(DefaultVCReserveRequest().WithTopic(topic).WithEndTime(endTime))
 }
 
+func (c AppClient) VCReserveWithTopicWithError(topic string, endTime time.Time) (*VCReserve, error) {
+	return c.VCReserveWithTopicWithContext(context.Background(), topic, endTime)
+}
+
+func (c AppClient) VCReserveWithTopicWithContext(ctx context.Context, topic string, endTime time.Time) (*VCReserve, error) {
+	return c.VCReserveWithContext(ctx, DefaultVCReserveRequest().WithTopic(topic).WithEndTime(endTime))
+}
+
 func (c AppClient) VCQueryParticipantList(meetingStartTime int64, meetingEndTime int64, meetingNo string) []Participant {
+	participants, err := c.VCQueryParticipantListWithError(meetingStartTime, meetingEndTime, meetingNo)
+	if err != nil {
+		logrus.WithField("MeetingNo", meetingNo).WithError(err).Warn("nil participant list return")
+		return nil
+	}
+	return participants
+}
+
+func (c AppClient) VCQueryParticipantListWithError(meetingStartTime int64, meetingEndTime int64, meetingNo string) ([]Participant, error) {
+	return c.VCQueryParticipantListWithContext(context.Background(), meetingStartTime, meetingEndTime, meetingNo)
+}
+
+func (c AppClient) VCQueryParticipantListWithContext(ctx context.Context, meetingStartTime int64, meetingEndTime int64, meetingNo string) ([]Participant, error) {
 	query := make(map[string]any)
 	query["meeting_start_time"] = strconv.FormatInt(meetingStartTime, 10)
 	query["meeting_end_time"] = strconv.FormatInt(meetingEndTime, 10)
 	query["meeting_no"] = meetingNo
 
-	info := c.getAllParticipants("get", "open-apis/vc/v1/participant_list", query, nil, nil, 100)
+	// the list of participants is not named "items", so GetAllPages can't be used here
+	info, err := c.getAllPages(ctx, "get", "open-apis/vc/v1/participant_list", query, nil, nil, 100, "participants")
+	if err != nil {
+		return nil, err
+	}
 
 	var participants []Participant
 	for _, participant_ := range info {
 		participant := Participant{}
 		data, err := json.Marshal(participant_)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		err = json.Unmarshal(data, &participant)
 		if err != nil {
-			return nil
+			return nil, err
 		}
 		participants = append(participants, participant)
 	}
-	return participants
-}
-
-// [Rewrite], because GetAllPages can't be used to VCQueryParticipantList
-func (c AppClient) getAllParticipants(method string, path string, query map[string]any, headers map[string]string, body any, page_size int) []any {
-	if page_size < 10 || page_size > 100 {
-		logrus.Info("page_size should be between 10 and 100")
-		return nil
-	}
-
-	var all_list []any
-	page_token := ""
-	has_more := true
-	queries := make(map[string]any)
-	if len(query) != 0 {
-		for k, v := range query {
-			queries[k] = v
-		}
-	}
-	queries["page_size"] = strconv.Itoa(page_size)
-
-	for {
-		if !has_more {
-			break
-		}
-		if page_token != "" {
-			queries["page_token"] = page_token
-		}
-
-		resp := c.Request(method, path, queries, headers, body)
-		if len(resp) == 0 {
-			return nil
-		}
-		if resp["participants"] == nil {
-			return []any{}
-		}
-
-		l := resp["participants"].([]any)
-		has_more = resp["has_more"].(bool)
-		interface_page_token, ok := resp["page_token"]
-		if ok {
-			page_token = interface_page_token.(string)
-		} else {
-			page_token = ""
-		}
-
-		all_list = append(all_list, l...)
-	}
-
-	return all_list
+	return participants, nil
 }
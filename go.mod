@@ -3,7 +3,7 @@ module github.com/YasyaKarasu/feishuapi
 go 1.18
 
 require (
-	github.com/robfig/cron/v3 v3.0.1
+	github.com/gorilla/websocket v1.5.0
 	github.com/sirupsen/logrus v1.9.0
 	github.com/spf13/viper v1.16.0
 )
@@ -0,0 +1,26 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBaseURLMockServer(t *testing.T) {
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/bot/v3/info", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+mockTenantAccessToken {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		writeJSON(w, map[string]any{"code": 0, "bot": map[string]any{"app_name": "mock", "open_id": "ou_bot"}})
+	})
+	// the trailing slash is trimmed
+	cli.Conf.BaseURL += "/"
+
+	info, err := cli.RobotGetInfoWithError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.OpenId != "ou_bot" {
+		t.Fatalf("got %+v", info)
+	}
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+// Sign a card callback like Feishu, sha1(timestamp + nonce + verification_token + body)
+func signedCardRequest(body []byte, verificationToken string) *http.Request {
+	req := httptest.NewRequest("POST", "/card", bytes.NewReader(body))
+	req.Header.Set("X-Lark-Request-Timestamp", "1700000000")
+	req.Header.Set("X-Lark-Request-Nonce", "nonce")
+	sum := sha1.Sum([]byte("1700000000" + "nonce" + verificationToken + string(body)))
+	req.Header.Set("X-Lark-Signature", hex.EncodeToString(sum[:]))
+	return req
+}
+
+func TestCardActionHandler(t *testing.T) {
+	var cli feishuapi.AppClient
+	cli.Conf.VerificationToken = "vtoken"
+
+	var approved *feishuapi.CardAction
+	router := feishuapi.NewCardActionRouter("").
+		On("approve", func(ctx context.Context, action *feishuapi.CardAction) (*feishuapi.CardActionResponse, error) {
+			approved = action
+			card := feishuapi.NewMessageCard().WithElements([]feishuapi.MessageCardElement{
+				feishuapi.NewMessageCardDiv().WithText(feishuapi.NewMessageCardPlainText().WithContent("approved").Build()).Build(),
+			}).Build()
+			return &feishuapi.CardActionResponse{Card: card, Toast: &feishuapi.CardToast{Type: feishuapi.CardToastSuccess, Content: "done"}}, nil
+		})
+	handler := cli.CardActionHandler(router.Handle)
+
+	// v1.0 callback with a signature
+	// the token of a v1.0 callback is the update token, not the verification token
+	body := []byte(`{"open_id":"ou_1","open_message_id":"om_1","token":"c-update","action":{"tag":"button","value":{"action":"approve","id":"42"}}}`)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedCardRequest(body, "vtoken"))
+
+	var resp map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("v1 callback: %d %s", rec.Code, rec.Body.String())
+	}
+	if approved == nil || approved.OpenId != "ou_1" || approved.Value["id"] != "42" || approved.Token != "c-update" || resp["toast"] == nil || resp["card"] == nil {
+		t.Fatalf("v1 callback: %+v %v", approved, resp)
+	}
+
+	// v2.0 callback for an action without handler
+	body = []byte(`{"schema":"2.0","header":{"token":"vtoken","event_type":"card.action.trigger"},"event":{"operator":{"open_id":"ou_2"},"action":{"tag":"button","value":{"action":"other"}},"context":{"open_message_id":"om_2"}}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedCardRequest(body, "vtoken"))
+	if rec.Code != http.StatusOK || bytes.TrimSpace(rec.Body.Bytes())[0] != '{' || approved.OpenId != "ou_1" {
+		t.Fatalf("v2 callback: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// a v2.0 callback with a wrong verification token
+	body = []byte(`{"schema":"2.0","header":{"token":"bad","event_type":"card.action.trigger"},"event":{"operator":{"open_id":"ou_2"},"action":{"value":{"action":"approve"}}}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedCardRequest(body, "vtoken"))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: %d", rec.Code)
+	}
+
+	// a forged callback without a signature
+	approved = nil
+	body = []byte(`{"open_id":"ou_3","open_message_id":"om_1","token":"c-update","action":{"value":{"action":"approve"}}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/card", bytes.NewReader(body)))
+	if rec.Code != http.StatusUnauthorized || approved != nil {
+		t.Fatalf("unsigned callback: %d", rec.Code)
+	}
+
+	// a callback signed with another token
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedCardRequest(body, "other"))
+	if rec.Code != http.StatusUnauthorized || approved != nil {
+		t.Fatalf("wrongly signed callback: %d", rec.Code)
+	}
+
+	// the url_verification request is checked by its token instead of a signature
+	body = []byte(`{"type":"url_verification","challenge":"c1","token":"vtoken"}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/card", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"c1"`)) {
+		t.Fatalf("url_verification: %d %s", rec.Code, rec.Body.String())
+	}
+	body = []byte(`{"type":"url_verification","challenge":"c1","token":"bad"}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/card", bytes.NewReader(body)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("url_verification with wrong token: %d", rec.Code)
+	}
+}
+
+func TestCardActionHandlerWithEncryptKeyOnly(t *testing.T) {
+	var cli feishuapi.AppClient
+	cli.Conf.EncryptKey = "ekey"
+
+	called := 0
+	handler := cli.CardActionHandler(func(ctx context.Context, action *feishuapi.CardAction) (*feishuapi.CardActionResponse, error) {
+		called++
+		return nil, nil
+	})
+	body := []byte(`{"open_id":"ou_1","open_message_id":"om_1","token":"c-update","action":{"value":{"action":"approve"}}}`)
+
+	// without a verification token, the sha1 signature can be computed by anyone
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedCardRequest(body, ""))
+	if rec.Code != http.StatusUnauthorized || called != 0 {
+		t.Fatalf("forged sha1 signature: %d, handler called %d times", rec.Code, called)
+	}
+
+	req := httptest.NewRequest("POST", "/card", bytes.NewReader(body))
+	req.Header.Set("X-Lark-Request-Timestamp", "1700000000")
+	req.Header.Set("X-Lark-Request-Nonce", "nonce")
+	sum := sha256.Sum256([]byte("1700000000" + "nonce" + "ekey" + string(body)))
+	req.Header.Set("X-Lark-Signature", hex.EncodeToString(sum[:]))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || called != 1 {
+		t.Fatalf("sha256 signature: %d, handler called %d times", rec.Code, called)
+	}
+}
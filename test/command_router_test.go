@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestCommandRouter(t *testing.T) {
+	var mu sync.Mutex
+	var sent []map[string]string
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/bot/v3/info", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0, "bot": map[string]any{"app_name": "bot", "open_id": "ou_bot"}})
+	})
+	mux.HandleFunc("/open-apis/im/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var msg map[string]string
+		json.Unmarshal(body, &msg)
+		mu.Lock()
+		sent = append(sent, msg)
+		mu.Unlock()
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"message_id": "om_reply"}})
+	})
+
+	var got *feishuapi.CommandContext
+	router := cli.NewCommandRouter().Register(feishuapi.Command{
+		Name:        "deploy",
+		Description: "Deploy a service",
+		Args: []feishuapi.CommandArg{
+			{Name: "service", Required: true},
+			{Name: "note", Rest: true},
+		},
+		Scope: feishuapi.CommandScopeGroup,
+		Handler: func(ctx context.Context, cmd *feishuapi.CommandContext) error {
+			got = cmd
+			return cmd.ReplyText(ctx, "deploying "+cmd.Arg("service"))
+		},
+	})
+
+	message := func(chatType string, text string) *feishuapi.MessageReceiveEvent {
+		content, _ := json.Marshal(map[string]string{"text": text})
+		return &feishuapi.MessageReceiveEvent{
+			SenderType: "user",
+			Message: feishuapi.EventMessage{
+				ChatId:      "oc_1",
+				ChatType:    chatType,
+				MessageType: "text",
+				Content:     string(content),
+				Mentions: []feishuapi.MessageMention{
+					{Key: "@_user_1", Id: feishuapi.EventUserId{OpenId: "ou_bot"}},
+					{Key: "@_user_10", Id: feishuapi.EventUserId{OpenId: "ou_other"}},
+				},
+			},
+		}
+	}
+
+	ctx := context.Background()
+	if err := router.HandleMessage(ctx, message("group", "@_user_1 /deploy api  ship it @_user_10")); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Arg("service") != "api" || got.Arg("note") != "ship it @_user_10" {
+		t.Fatalf("unexpected command %+v", got)
+	}
+
+	// restricted to group chats, a missing argument, the help card, a plain message,
+	// and unknown commands in a group, which are answered only when the bot is mentioned
+	for _, event := range []*feishuapi.MessageReceiveEvent{
+		message("p2p", "/deploy api"),
+		message("group", "@_user_1 /deploy"),
+		message("p2p", "/help"),
+		message("p2p", "hello"),
+		message("group", "/usr/bin is full @_user_10"),
+		message("group", "@_user_1 /rollback"),
+	} {
+		if err := router.HandleMessage(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 5 {
+		t.Fatalf("expected 5 replies, got %v", sent)
+	}
+	if !strings.Contains(sent[1]["content"], "only available in group") || !strings.Contains(sent[2]["content"], "missing argument service") {
+		t.Fatalf("unexpected replies %v", sent)
+	}
+	// deploy isn't usable in p2p chats, so it isn't in the help card there
+	if sent[3]["msg_type"] != "interactive" || strings.Contains(sent[3]["content"], "deploy") {
+		t.Fatalf("unexpected help card %v", sent[3])
+	}
+	if sent[4]["msg_type"] != "interactive" || !strings.Contains(sent[4]["content"], "Unknown command /rollback") {
+		t.Fatalf("unexpected reply to unknown command %v", sent[4])
+	}
+}
+
+func TestCommandRouterRejectsNilHandler(t *testing.T) {
+	var cli feishuapi.AppClient
+	router := cli.NewCommandRouter()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Register should panic on a nil handler")
+		}
+	}()
+	router.Register(feishuapi.Command{Name: "deploy"})
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestGetAllPagesWithCancelledContext(t *testing.T) {
+	var cli feishuapi.AppClient
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cli.EmployeeGetAllInfoWithContext(ctx, feishuapi.UserId)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// cancelled after the first page of a walk which has more pages
+	var pageTokens []string
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/ehr/v1/employees", func(w http.ResponseWriter, r *http.Request) {
+		pageTokens = append(pageTokens, r.URL.Query().Get("page_token"))
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+			"items":      []any{map[string]any{"user_id": "u1"}},
+			"has_more":   true,
+			"page_token": "page-2",
+		}})
+	})
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	cli.Middlewares = []feishuapi.Middleware{func(next feishuapi.RoundTripFunc) feishuapi.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if req.URL.Path == "/open-apis/ehr/v1/employees" {
+				cancel()
+			}
+			return resp, err
+		}
+	}}
+
+	_, err = cli.EmployeeGetAllInfoWithContext(ctx, feishuapi.UserId)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(pageTokens) != 1 || pageTokens[0] != "" {
+		t.Fatalf("no page should be requested after cancel, got the pages %q", pageTokens)
+	}
+}
@@ -123,4 +123,5 @@ func SetAppClientConfig(c *feishuapi.AppClient) {
 	c.Conf.AppSecret = viper.GetString("feishu.APP_SECRET")
 	c.Conf.VerificationToken = viper.GetString("feishu.VERIFICATION_TOKEN")
 	c.Conf.EncryptKey = viper.GetString("feishu.ENCRYPT_KEY")
+	c.Conf.BaseURL = viper.GetString("feishu.BASE_URL")
 }
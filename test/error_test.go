@@ -0,0 +1,73 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestAPIErrorHelpers(t *testing.T) {
+	rateLimited := &feishuapi.APIError{HTTPStatus: http.StatusOK, Code: feishuapi.CodeRateLimited}
+	tooMany := &feishuapi.APIError{HTTPStatus: http.StatusTooManyRequests}
+	denied := &feishuapi.APIError{HTTPStatus: http.StatusBadRequest, Code: feishuapi.CodeAppScopeNotEnabled}
+	notFound := &feishuapi.APIError{HTTPStatus: http.StatusNotFound}
+
+	if !feishuapi.IsRateLimited(rateLimited) || !feishuapi.IsRateLimited(tooMany) {
+		t.Error("rate limit errors should be recognized")
+	}
+	if feishuapi.IsRateLimited(denied) {
+		t.Error("permission error should not be rate limited")
+	}
+	if !feishuapi.IsPermissionDenied(denied) {
+		t.Error("permission error should be recognized")
+	}
+	if !feishuapi.IsNotFound(fmt.Errorf("get record: %w", notFound)) {
+		t.Error("wrapped not found error should be recognized")
+	}
+	if feishuapi.IsNotFound(errors.New("network down")) {
+		t.Error("plain error should not be an APIError")
+	}
+}
+
+func TestRequestWithErrorParsesAPIError(t *testing.T) {
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/mock", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Tt-Logid", "log-1")
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]any{"code": feishuapi.CodeAppScopeNotEnabled, "msg": "scope not enabled"})
+	})
+
+	_, err := cli.RequestWithError("get", "open-apis/mock", nil, nil, nil)
+	var apiErr *feishuapi.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.Code != feishuapi.CodeAppScopeNotEnabled || apiErr.Msg != "scope not enabled" || apiErr.HTTPStatus != http.StatusBadRequest || apiErr.LogId != "log-1" {
+		t.Fatalf("got %+v", apiErr)
+	}
+	if !feishuapi.IsPermissionDenied(err) {
+		t.Error("permission error should be recognized")
+	}
+}
+
+func TestRequestWithErrorRejectsUnsupportedQuery(t *testing.T) {
+	var sent int32
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/mock", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		writeJSON(w, map[string]any{"code": 0})
+	})
+
+	_, err := cli.RequestWithError("get", "open-apis/mock", map[string]any{"flag": true}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "flag") {
+		t.Fatalf("expected an error for the bool query, got %v", err)
+	}
+	if atomic.LoadInt32(&sent) != 0 {
+		t.Fatal("the request should not be sent")
+	}
+}
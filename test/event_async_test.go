@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+type recordMetrics struct {
+	mu      sync.Mutex
+	handled int
+	failed  int
+	dropped map[string]int
+}
+
+func (m *recordMetrics) ObserveEventHandled(eventType string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handled++
+	if err != nil {
+		m.failed++
+	}
+}
+
+func (m *recordMetrics) ObserveEventDropped(eventType string, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropped[reason]++
+}
+
+func TestAsyncEventHandler(t *testing.T) {
+	release := make(chan struct{})
+	metrics := &recordMetrics{dropped: make(map[string]int)}
+	store := feishuapi.NewMemorySeenStore()
+	h := feishuapi.NewAsyncEventHandler(func(ctx context.Context, event *feishuapi.Event) error {
+		<-release
+		if event.EventId == "e2" {
+			return errors.New("fail")
+		}
+		return nil
+	}, feishuapi.AsyncEventOptions{Workers: 1, QueueSize: 1, SeenStore: store, Metrics: metrics})
+
+	// e1 is taken by the worker, e2 waits in the queue, so the queue is full for e3
+	if err := h.Handle(context.Background(), &feishuapi.Event{EventId: "e1"}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 50)
+	if err := h.Handle(context.Background(), &feishuapi.Event{EventId: "e2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Handle(context.Background(), &feishuapi.Event{EventId: "e1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Handle(context.Background(), &feishuapi.Event{EventId: "e3"}); !errors.Is(err, feishuapi.ErrEventQueueFull) {
+		t.Fatalf("expected ErrEventQueueFull, got %v", err)
+	}
+
+	close(release)
+	if err := h.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.handled != 2 || metrics.failed != 1 || metrics.dropped["duplicate"] != 1 || metrics.dropped["queue_full"] != 1 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+
+	// e3 is forgotten after it is rejected, so its redelivery isn't a duplicate
+	if first, _ := store.MarkSeen(context.Background(), "e3", time.Minute); !first {
+		t.Fatal("expected e3 to be forgotten")
+	}
+	if first, _ := store.MarkSeen(context.Background(), "e1", time.Minute); first {
+		t.Fatal("expected e1 to be remembered")
+	}
+}
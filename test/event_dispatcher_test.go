@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestEventDispatcher(t *testing.T) {
+	var received *feishuapi.MessageReceiveEvent
+	var added *feishuapi.ChatMemberAddedEvent
+	var unknown []string
+
+	d := feishuapi.NewEventDispatcher().
+		OnMessageReceive(func(ctx context.Context, event *feishuapi.MessageReceiveEvent) error {
+			received = event
+			return nil
+		}).
+		OnChatMemberAdded(func(ctx context.Context, event *feishuapi.ChatMemberAddedEvent) error {
+			added = event
+			return nil
+		}).
+		OnUnknown(func(ctx context.Context, event *feishuapi.Event) error {
+			unknown = append(unknown, event.EventType)
+			return nil
+		})
+
+	events := []*feishuapi.Event{
+		{
+			EventType: feishuapi.EventTypeMessageReceive,
+			Event:     json.RawMessage(`{"sender":{"sender_id":{"open_id":"ou_1"},"sender_type":"user"},"message":{"message_id":"om_1","chat_id":"oc_1","message_type":"text","content":"{\"text\":\"hi\"}","create_time":"1700000000000"}}`),
+		},
+		{
+			EventType: feishuapi.EventTypeChatMemberAdded,
+			TenantKey: "tk",
+			Event:     json.RawMessage(`{"chat_id":"oc_1","name":"group","users":[{"name":"u","user_id":{"open_id":"ou_2"}}]}`),
+		},
+		{EventType: "im.chat.disbanded_v1", Event: json.RawMessage(`{}`)},
+	}
+	for _, event := range events {
+		if err := d.Dispatch(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if received == nil || received.Sender.OpenId != "ou_1" || received.Message.Content != `{"text":"hi"}` || received.Message.CreateTime.Unix() != 1700000000 {
+		t.Fatalf("message receive: %+v", received)
+	}
+	if added == nil || added.Chat.ChatId != "oc_1" || added.Chat.TenantKey != "tk" || len(added.Users) != 1 || added.Users[0].OpenId != "ou_2" {
+		t.Fatalf("chat member added: %+v", added)
+	}
+	if len(unknown) != 1 || unknown[0] != "im.chat.disbanded_v1" {
+		t.Fatalf("fallback: %v", unknown)
+	}
+
+	bad := &feishuapi.Event{EventType: feishuapi.EventTypeMessageReceive, Event: json.RawMessage(`[]`)}
+	if err := d.Dispatch(context.Background(), bad); err == nil {
+		t.Fatal("expected a decode error")
+	}
+}
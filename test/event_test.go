@@ -0,0 +1,95 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func encryptEvent(t *testing.T, key string, payload string) []byte {
+	k := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	padding := aes.BlockSize - len(payload)%aes.BlockSize
+	plain := append([]byte(payload), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	data := make([]byte, aes.BlockSize+len(plain))
+	rand.Read(data[:aes.BlockSize])
+	cipher.NewCBCEncrypter(block, data[:aes.BlockSize]).CryptBlocks(data[aes.BlockSize:], plain)
+
+	body, _ := json.Marshal(map[string]string{"encrypt": base64.StdEncoding.EncodeToString(data)})
+	return body
+}
+
+func signedEventRequest(key string, body []byte) *http.Request {
+	req := httptest.NewRequest("POST", "/event", bytes.NewReader(body))
+	req.Header.Set("X-Lark-Request-Timestamp", "1700000000")
+	req.Header.Set("X-Lark-Request-Nonce", "nonce")
+	sum := sha256.Sum256([]byte("1700000000" + "nonce" + key + string(body)))
+	req.Header.Set("X-Lark-Signature", hex.EncodeToString(sum[:]))
+	return req
+}
+
+func TestEventWebhookHandler(t *testing.T) {
+	var cli feishuapi.AppClient
+	cli.Conf.VerificationToken = "vtoken"
+	cli.Conf.EncryptKey = "ekey"
+
+	var got []*feishuapi.Event
+	handler := cli.EventWebhookHandler(func(ctx context.Context, event *feishuapi.Event) error {
+		got = append(got, event)
+		return nil
+	})
+
+	// url_verification
+	rec := httptest.NewRecorder()
+	body := encryptEvent(t, "ekey", `{"type":"url_verification","token":"vtoken","challenge":"abc"}`)
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/event", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"challenge":"abc"`)) {
+		t.Fatalf("url_verification: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// v2.0 event
+	body = encryptEvent(t, "ekey", `{"schema":"2.0","header":{"event_id":"e1","event_type":"im.message.receive_v1","create_time":"1700000000000","token":"vtoken","app_id":"cli"},"event":{"message":{}}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedEventRequest("ekey", body))
+	if rec.Code != http.StatusOK || len(got) != 1 || got[0].EventId != "e1" || got[0].EventType != "im.message.receive_v1" || got[0].CreateTime.Unix() != 1700000000 {
+		t.Fatalf("v2 event: %d %+v", rec.Code, got)
+	}
+
+	// v1.0 event
+	body = encryptEvent(t, "ekey", `{"uuid":"u1","token":"vtoken","ts":"1700000000.5","type":"event_callback","event":{"type":"approval","app_id":"cli"}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedEventRequest("ekey", body))
+	if rec.Code != http.StatusOK || len(got) != 2 || got[1].Schema != "1.0" || got[1].EventId != "u1" || got[1].EventType != "approval" {
+		t.Fatalf("v1 event: %d %+v", rec.Code, got)
+	}
+
+	// a forged signature
+	req := signedEventRequest("other", body)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || len(got) != 2 {
+		t.Fatalf("forged signature: %d", rec.Code)
+	}
+
+	// a wrong verification token
+	body = encryptEvent(t, "ekey", `{"schema":"2.0","header":{"event_id":"e2","event_type":"x","token":"bad"},"event":{}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedEventRequest("ekey", body))
+	if rec.Code != http.StatusUnauthorized || len(got) != 2 {
+		t.Fatalf("wrong token: %d", rec.Code)
+	}
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestLoginHandlerRedirect(t *testing.T) {
+	var cli feishuapi.AppClient
+	cli.Conf.AppId = "cli_test"
+	cli.Conf.AppSecret = "secret"
+	conf := feishuapi.LoginConfig{RedirectURL: "https://portal.example.com/login/callback"}
+
+	rec := httptest.NewRecorder()
+	cli.LoginHandler(conf).ServeHTTP(rec, httptest.NewRequest("GET", "/login?redirect=//evil.example.com", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("expected redirect, got %d", rec.Code)
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := location.Query()
+	if query.Get("app_id") != "cli_test" || query.Get("redirect_uri") != conf.RedirectURL || query.Get("state") == "" {
+		t.Fatalf("unexpected authorize url %s", location)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected the state cookie, got %v", cookies)
+	}
+
+	// a tampered state is rejected before the code is exchanged
+	callback := httptest.NewRequest("GET", "/login/callback?code=c&state="+url.QueryEscape(query.Get("state")+"x"), nil)
+	callback.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	cli.LoginCallbackHandler(conf).ServeHTTP(rec, callback)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected tampered state to be rejected, got %d", rec.Code)
+	}
+
+	// so is a valid state without the cookie of the browser which started the login
+	callback = httptest.NewRequest("GET", "/login/callback?code=c&state="+url.QueryEscape(query.Get("state")), nil)
+	rec = httptest.NewRecorder()
+	cli.LoginCallbackHandler(conf).ServeHTTP(rec, callback)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing cookie to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestLoginCallbackHandler(t *testing.T) {
+	var exchanges, userInfos int
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/authen/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["grant_type"] != "authorization_code" || body["code"] != "code-1" || r.Header.Get("Authorization") != "Bearer "+mockTenantAccessToken {
+			t.Errorf("unexpected code exchange %v with %q", body, r.Header.Get("Authorization"))
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+			"access_token":       "u-login",
+			"refresh_token":      "ur-login",
+			"open_id":            "ou_login",
+			"expires_in":         7200,
+			"refresh_expires_in": 2592000,
+		}})
+	})
+	mux.HandleFunc("/open-apis/authen/v1/user_info", func(w http.ResponseWriter, r *http.Request) {
+		userInfos++
+		if r.Header.Get("Authorization") != "Bearer u-login" {
+			t.Errorf("user_info requested with %q", r.Header.Get("Authorization"))
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"name": "Alice", "open_id": "ou_login"}})
+	})
+
+	var logins []*feishuapi.LoginResult
+	conf := feishuapi.LoginConfig{
+		RedirectURL: "https://portal.example.com/login/callback",
+		OnLogin: func(w http.ResponseWriter, r *http.Request, result *feishuapi.LoginResult) error {
+			logins = append(logins, result)
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: result.User.OpenId})
+			http.Redirect(w, r, result.RedirectTo, http.StatusFound)
+			return nil
+		},
+	}
+
+	// start the login from the local page which the browser goes back to
+	rec := httptest.NewRecorder()
+	cli.LoginHandler(conf).ServeHTTP(rec, httptest.NewRequest("GET", "/login?redirect=/dashboard", nil))
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.String() != cli.AuthorizeURL(conf.RedirectURL, location.Query().Get("state")) || location.Path != "/open-apis/authen/v1/index" {
+		t.Fatalf("unexpected authorize url %s", location)
+	}
+	state := location.Query().Get("state")
+	stateCookie := rec.Result().Cookies()[0]
+
+	// Feishu redirects the browser back with the code and the state
+	callback := httptest.NewRequest("GET", "/login/callback?code=code-1&state="+url.QueryEscape(state), nil)
+	callback.AddCookie(stateCookie)
+	rec = httptest.NewRecorder()
+	cli.LoginCallbackHandler(conf).ServeHTTP(rec, callback)
+
+	if exchanges != 1 || userInfos != 1 {
+		t.Fatalf("expected 1 code exchange and 1 user_info request, got %d and %d", exchanges, userInfos)
+	}
+	if len(logins) != 1 {
+		t.Fatalf("OnLogin should be called once, got %d", len(logins))
+	}
+	result := logins[0]
+	if result.Token.Access_token != "u-login" || result.Token.Refresh_token != "ur-login" || result.User.Name != "Alice" || result.User.OpenId != "ou_login" || result.RedirectTo != "/dashboard" {
+		t.Fatalf("unexpected login result %+v, token %+v, user %+v", result, result.Token, result.User)
+	}
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/dashboard" {
+		t.Fatalf("expected redirect to /dashboard, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	// the state cookie is cleared and the session is set up by OnLogin
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range rec.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	if cookies[stateCookie.Name] == nil || cookies[stateCookie.Name].MaxAge >= 0 || cookies["session"] == nil || cookies["session"].Value != "ou_login" {
+		t.Fatalf("unexpected cookies %v", rec.Result().Cookies())
+	}
+
+	// without OnLogin the browser is redirected to where the login started
+	conf.OnLogin = nil
+	rec = httptest.NewRecorder()
+	cli.LoginCallbackHandler(conf).ServeHTTP(rec, callback)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/dashboard" || exchanges != 2 || userInfos != 2 {
+		t.Fatalf("expected redirect to /dashboard, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
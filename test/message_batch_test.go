@@ -0,0 +1,92 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessageBatchSend(t *testing.T) {
+	var sent []map[string]any
+	recalled := false
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/message/v4/batch_send", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = append(sent, body)
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+			"message_id":             "bm_1",
+			"invalid_department_ids": []any{"od_gone"},
+			"invalid_open_ids":       []any{},
+		}})
+	})
+	mux.HandleFunc("/open-apis/im/v1/batch_messages/bm_1/get_progress", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+			"batch_message_send_progress":   map[string]any{"valid_user_ids_count": 120, "success_user_ids_count": 100, "read_user_ids_count": 30},
+			"batch_message_recall_progress": map[string]any{"recall": recalled, "recall_count": 0},
+		}})
+	})
+	mux.HandleFunc("/open-apis/im/v1/batch_messages/bm_1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			http.NotFound(w, r)
+			return
+		}
+		recalled = true
+		writeJSON(w, map[string]any{"code": 0})
+	})
+
+	target := feishuapi.BatchMessageTarget{DepartmentIds: []string{"od_1", "od_gone"}, OpenIds: []string{"ou_1"}}
+	batch, err := cli.MessageBatchSendWithError(target, feishuapi.Text, "announcement")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.MessageId != "bm_1" || len(batch.InvalidDepartmentIds) != 1 || batch.InvalidDepartmentIds[0] != "od_gone" || len(batch.InvalidOpenIds) != 0 {
+		t.Fatalf("unexpected batch %+v", batch)
+	}
+	if sent[0]["content"].(map[string]any)["text"] != "announcement" || len(sent[0]["department_ids"].([]any)) != 2 || sent[0]["user_ids"] != nil {
+		t.Fatalf("unexpected text body %v", sent[0])
+	}
+
+	post := `{"zh_cn":{"title":"t","content":[]}}`
+	if _, err := cli.MessageBatchSendWithError(target, feishuapi.Post, post); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent[1]["content"].(map[string]any)["post"].(map[string]any)["zh_cn"]; !ok {
+		t.Fatalf("unexpected post body %v", sent[1])
+	}
+
+	card := `{"header":{"title":{"tag":"plain_text","content":"c"}}}`
+	if _, err := cli.MessageBatchSendWithError(target, feishuapi.Interactive, card); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent[2]["card"].(map[string]any)["header"]; !ok || sent[2]["content"] != nil {
+		t.Fatalf("unexpected card body %v", sent[2])
+	}
+
+	if _, err := cli.MessageBatchSendWithError(target, feishuapi.File, "file_1"); err == nil {
+		t.Fatal("file messages should be rejected")
+	}
+	if _, err := cli.MessageBatchSendWithError(feishuapi.BatchMessageTarget{}, feishuapi.Text, "x"); err == nil {
+		t.Fatal("a batch without receivers should be rejected")
+	}
+	if len(sent) != 3 {
+		t.Fatalf("rejected batches should not be sent, got %d requests", len(sent))
+	}
+
+	progress, err := cli.MessageBatchProgressWithError(batch.MessageId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.ValidUserCount != 120 || progress.SuccessUserCount != 100 || progress.ReadUserCount != 30 || progress.Recalled {
+		t.Fatalf("unexpected progress %+v", progress)
+	}
+
+	if err := cli.MessageBatchRecallWithError(batch.MessageId); err != nil {
+		t.Fatal(err)
+	}
+	if progress, err := cli.MessageBatchProgressWithError(batch.MessageId); err != nil || !progress.Recalled {
+		t.Fatalf("batch should be recalled, got %+v, %v", progress, err)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestParseMessageCardRoundTrip(t *testing.T) {
+	plain := func(content string) *feishuapi.MessageCardPlainText {
+		return feishuapi.NewMessageCardPlainText().WithContent(content)
+	}
+	options := []feishuapi.MessageCardOption{
+		*feishuapi.NewMessageCardOption().WithText(plain("one")).WithValue("1"),
+		*feishuapi.NewMessageCardOption().WithText(plain("two")).WithMultiURL(feishuapi.NewMessageCardURL().WithURL("https://example.com").Build()),
+	}
+	card := feishuapi.NewMessageCard().
+		WithConfig(feishuapi.NewMessageCardConfig().WithEnableForward(true).WithUpdateMulti(false)).
+		WithHeader(feishuapi.NewMessageCardHeader().WithTitle(plain("Title")).WithTemplate(feishuapi.TemplateRed)).
+		WithElements([]feishuapi.MessageCardElement{
+			feishuapi.NewMessageCardDiv().
+				WithText(feishuapi.NewMessageCardLarkMarkdown().WithContent("**bold**")).
+				WithFields([]*feishuapi.MessageCardField{feishuapi.NewMessageCardField().WithIsShort(true).WithText(plain("field").WithLines(2))}).
+				WithExtra(feishuapi.NewMessageCardButton().WithText(plain("extra")).WithType(feishuapi.TypePrimary).WithValue(map[string]any{"action": "extra"})),
+			feishuapi.NewMessageCardMarkdown().WithContent("[link]($urlVal)").WithTextAlign(feishuapi.TextAlignCenter).
+				WithHref(map[string]*feishuapi.MessageCardURL{"urlVal": feishuapi.NewMessageCardURL().WithURL("https://example.com").Build()}),
+			feishuapi.NewMessageCardHr(),
+			feishuapi.NewMessageCardImage().WithImageKey("img_1").WithAlt(plain("alt")).WithTitle(plain("image")).WithMode(feishuapi.ModeFitHorizontal).WithPreview(true),
+			feishuapi.NewMessageCardNote().WithElements([]feishuapi.MessageCardNoteElement{
+				plain("note"),
+				feishuapi.NewMessageCardImage().WithImageKey("img_2").WithAlt(plain("icon")),
+			}),
+			feishuapi.NewMessageCardColumnSet().WithFlexMode(feishuapi.FlexModeBisect).WithBackgroundStyle(feishuapi.BackgroundStyleGrey).
+				WithColumns([]feishuapi.MessageCardColumn{
+					*feishuapi.NewMessageCardColumn().WithWeight(2).WithVerticalAlign(feishuapi.VerticalAlignTop).
+						WithElements([]feishuapi.MessageCardElement{feishuapi.NewMessageCardMarkdown().WithContent("left")}),
+				}),
+			feishuapi.NewMessageCardAction().WithLayout(feishuapi.LayoutFlow).WithActions([]feishuapi.MessageCardActionElement{
+				feishuapi.NewMessageCardButton().WithText(plain("approve")).WithType(feishuapi.TypeDanger).
+					WithConfirm(feishuapi.NewMessageCardConfirm().WithTitle(plain("sure?")).WithText(plain("really"))),
+				feishuapi.NewMessageCardDatePicker().WithMessageCardDatePickerBase(feishuapi.NewMessageCardDatePickerBase().WithInitialDate("2024-01-01")),
+				feishuapi.NewMessageCardPickerTime().WithMessageCardDatePickerBase(feishuapi.NewMessageCardDatePickerBase().WithPlaceHolder(plain("time"))),
+				feishuapi.NewMessageCardPickerDateTime().WithMessageCardDatePickerBase(feishuapi.NewMessageCardDatePickerBase().WithInitialDateTime("2024-01-01 10:00")),
+				feishuapi.NewMessageCardOverflow().WithOptions(options),
+				feishuapi.NewMessageCardSelectStatic().WithMessageCardSelectMenuBase(feishuapi.NewMessageCardSelectMenuBase().WithPlaceHolder(plain("pick")).WithOptions(options)),
+				feishuapi.NewMessageCardSelectPerson().WithMessageCardSelectMenuBase(feishuapi.NewMessageCardSelectMenuBase().WithInitialOption("ou_1").WithOptions([]feishuapi.MessageCardOption{*feishuapi.NewMessageCardOption().WithValue("ou_1")})),
+			}),
+		})
+	card.CardLink = feishuapi.NewCardLink().WithURL("https://example.com")
+
+	want, err := card.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := feishuapi.ParseMessageCard([]byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsed.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("card changed after round trip\nwant %s\ngot  %s", want, got)
+	}
+
+	div, ok := parsed.Elements[0].(*feishuapi.MessageCardDiv)
+	if !ok {
+		t.Fatalf("first element should be a div, got %T", parsed.Elements[0])
+	}
+	if _, ok := div.Extra.(*feishuapi.MessageCardButton); !ok {
+		t.Fatalf("extra should be a button, got %T", div.Extra)
+	}
+	div.WithText(feishuapi.NewMessageCardLarkMarkdown().WithContent("patched"))
+	if patched, err := parsed.String(); err != nil || !strings.Contains(patched, "patched") {
+		t.Fatalf("patched card %s, %v", patched, err)
+	}
+}
+
+func TestParseMessageCardErrors(t *testing.T) {
+	if _, err := feishuapi.ParseMessageCard([]byte(`{"elements":[{"tag":"unknown"}]}`)); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("expected unknown tag error, got %v", err)
+	}
+	if _, err := feishuapi.ParseMessageCard([]byte(`{"elements":[{"tag":"div","text":{"tag":"hr"}}]}`)); err == nil {
+		t.Fatal("hr should not be accepted as the text of a div")
+	}
+	if _, err := feishuapi.ParseMessageCard([]byte(`{"elements":[{"tag":"action","actions":[{"tag":"markdown","content":"x"}]}]}`)); err == nil {
+		t.Fatal("markdown should not be accepted as an action")
+	}
+}
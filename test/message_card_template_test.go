@@ -0,0 +1,110 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessageCardTemplateRef(t *testing.T) {
+	content, err := feishuapi.NewMessageCardTemplateRef("ctp_1").WithVersionName("1.0.0").WithVariable("host", "db-1").String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(content), &got); err != nil {
+		t.Fatal(err)
+	}
+	data := got["data"].(map[string]any)
+	if got["type"] != "template" || data["template_id"] != "ctp_1" || data["template_version_name"] != "1.0.0" || data["template_variable"].(map[string]any)["host"] != "db-1" {
+		t.Fatalf("unexpected template content %s", content)
+	}
+
+	if _, err := feishuapi.NewMessageCardTemplateRef("").String(); err == nil {
+		t.Fatal("empty template_id should be rejected")
+	}
+}
+
+func TestMessageCardTemplateRender(t *testing.T) {
+	template := feishuapi.NewMessageCardTemplate(feishuapi.NewMessageCard().
+		WithHeader(feishuapi.NewMessageCardHeader().WithTitle(feishuapi.NewMessageCardPlainText().WithContent("${service} alerts"))).
+		WithElements([]feishuapi.MessageCardElement{
+			feishuapi.NewMessageCardRepeat().WithItems("alerts").WithAs("alert").WithElements([]feishuapi.MessageCardElement{
+				feishuapi.NewMessageCardDiv().WithText(feishuapi.NewMessageCardLarkMarkdown().WithContent("**${alert.host}** ${alert.level} in ${service}")),
+			}),
+			feishuapi.NewMessageCardAction().WithActions([]feishuapi.MessageCardActionElement{
+				feishuapi.NewMessageCardRepeat().WithItems("buttons").WithElements([]feishuapi.MessageCardElement{
+					feishuapi.NewMessageCardButton().WithText(feishuapi.NewMessageCardPlainText().WithContent("${item}")),
+				}),
+			}),
+		}))
+
+	vars := map[string]any{
+		"service": "billing",
+		"alerts": []map[string]any{
+			{"host": "db-1", "level": "P0"},
+			{"host": "db-2", "level": "P2"},
+		},
+		"buttons": []string{"ack", "mute"},
+	}
+	card, err := template.Render(vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := card.Validate(); err != nil {
+		t.Fatalf("rendered card is invalid: %v", err)
+	}
+	if card.Header.Title.Content != "billing alerts" || len(card.Elements) != 3 {
+		t.Fatalf("unexpected card %+v", card)
+	}
+	second := card.Elements[1].(*feishuapi.MessageCardDiv).Text.(*feishuapi.MessageCardLarkMarkdown)
+	if second.Content != "**db-2** P2 in billing" {
+		t.Fatalf("unexpected content %q", second.Content)
+	}
+	actions := card.Elements[2].(*feishuapi.MessageCardAction).Actions
+	if len(actions) != 2 || actions[1].(*feishuapi.MessageCardButton).Text.(*feishuapi.MessageCardPlainText).Content != "mute" {
+		t.Fatalf("unexpected actions %+v", actions)
+	}
+
+	// the template can be rendered again
+	again, err := template.Render(map[string]any{"service": "search", "alerts": []any{}, "buttons": []any{"ack"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Header.Title.Content != "search alerts" || len(again.Elements) != 1 {
+		t.Fatalf("unexpected card %+v", again)
+	}
+
+	if _, err := template.Render(map[string]any{"alerts": []any{}, "buttons": []any{}}); err == nil || !strings.Contains(err.Error(), "service") {
+		t.Fatalf("expected missing variable error, got %v", err)
+	}
+	if _, err := template.Render(map[string]any{"service": "x", "alerts": "no", "buttons": []any{}}); err == nil || !strings.Contains(err.Error(), "not a list") {
+		t.Fatalf("expected not a list error, got %v", err)
+	}
+}
+
+func TestParseMessageCardTemplate(t *testing.T) {
+	template, err := feishuapi.ParseMessageCardTemplate([]byte(`{
+		"elements": [
+			{"tag": "note", "elements": [
+				{"tag": "repeat", "items": "tags", "elements": [{"tag": "plain_text", "content": "#${item}"}]}
+			]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	card, err := template.Render(map[string]any{"tags": []any{"db", "prod"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := card.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"elements":[{"elements":[{"content":"#db","tag":"plain_text"},{"content":"#prod","tag":"plain_text"}],"tag":"note"}]}` {
+		t.Fatalf("unexpected card %s", content)
+	}
+}
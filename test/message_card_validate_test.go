@@ -0,0 +1,183 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessageCardValidate(t *testing.T) {
+	plain := func(content string) *feishuapi.MessageCardPlainText {
+		return feishuapi.NewMessageCardPlainText().WithContent(content)
+	}
+	valid := feishuapi.NewMessageCard().
+		WithHeader(feishuapi.NewMessageCardHeader().WithTitle(plain("title"))).
+		WithElements([]feishuapi.MessageCardElement{
+			feishuapi.NewMessageCardDiv().WithText(plain("text")),
+			feishuapi.NewMessageCardAction().WithActions([]feishuapi.MessageCardActionElement{
+				feishuapi.NewMessageCardButton().WithText(plain("ok")),
+			}),
+		})
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid card reported %v", err)
+	}
+
+	var options []feishuapi.MessageCardOption
+	for i := 0; i < 11; i++ {
+		options = append(options, *feishuapi.NewMessageCardOption().WithText(plain("o")).WithValue("v"))
+	}
+	invalid := feishuapi.NewMessageCard().
+		WithHeader(feishuapi.NewMessageCardHeader()).
+		WithElements([]feishuapi.MessageCardElement{
+			feishuapi.NewMessageCardDiv().WithText(plain("text")).WithExtra(feishuapi.NewMessageCardSelectStatic()),
+			feishuapi.NewMessageCardNote().WithElements([]feishuapi.MessageCardNoteElement{plain("ok"), feishuapi.NewMessageCardImage()}),
+			feishuapi.NewMessageCardAction().WithActions([]feishuapi.MessageCardActionElement{
+				feishuapi.NewMessageCardButton(),
+				feishuapi.NewMessageCardOverflow().WithOptions(options),
+			}),
+			feishuapi.NewMessageCardColumnSet().WithColumns([]feishuapi.MessageCardColumn{
+				*feishuapi.NewMessageCardColumn().WithWeight(6),
+			}),
+		})
+
+	var errs feishuapi.MessageCardValidationErrors
+	if err := invalid.Validate(); !errors.As(err, &errs) {
+		t.Fatalf("expected MessageCardValidationErrors, got %v", err)
+	}
+	want := []string{
+		"header.title: title is required",
+		"elements[0].extra: placeholder is required",
+		"elements[1].elements[1].img_key: image key is required",
+		"elements[1].elements[1].alt: alt is required",
+		"elements[2].actions[0].text: text is required",
+		"elements[2].actions[1].options: at most 10 options are allowed, got 11",
+		"elements[3].flex_mode: flex_mode is required",
+		"elements[3].columns[0].weight: weight should be between 1 and 5, got 6",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("error %d: want %q, got %q", i, want[i], err.Error())
+		}
+	}
+
+	large := feishuapi.NewMessageCard().WithElements([]feishuapi.MessageCardElement{
+		feishuapi.NewMessageCardMarkdown().WithContent(strings.Repeat("a", 40*1024)),
+	})
+	if err := large.Validate(); !errors.As(err, &errs) || len(errs) != 1 || !strings.Contains(errs[0].Error(), "larger than") {
+		t.Fatalf("expected size error, got %v", err)
+	}
+
+	nested := feishuapi.NewMessageCard().WithElements([]feishuapi.MessageCardElement{
+		feishuapi.NewMessageCardColumnSet().WithFlexMode(feishuapi.FlexModeNone).WithColumns([]feishuapi.MessageCardColumn{
+			*feishuapi.NewMessageCardColumn().WithElements([]feishuapi.MessageCardElement{
+				feishuapi.NewMessageCardColumnSet().WithFlexMode(feishuapi.FlexModeNone),
+			}),
+		}),
+	})
+	if err := nested.Validate(); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "elements[0].columns[0].elements[0]" {
+		t.Fatalf("expected nesting error, got %v", err)
+	}
+
+	// the interactive and text elements can't be put in the elements of a card or a column directly
+	misplaced := feishuapi.NewMessageCard().WithElements([]feishuapi.MessageCardElement{
+		feishuapi.NewMessageCardButton().WithText(plain("ok")),
+		plain("text"),
+		feishuapi.NewMessageCardRepeat().WithItems("rows"),
+		feishuapi.NewMessageCardHr(),
+		feishuapi.NewMessageCardColumnSet().WithFlexMode(feishuapi.FlexModeNone).WithColumns([]feishuapi.MessageCardColumn{
+			*feishuapi.NewMessageCardColumn().WithElements([]feishuapi.MessageCardElement{
+				feishuapi.NewMessageCardSelectStatic(),
+				feishuapi.NewMessageCardMarkdown().WithContent("ok"),
+			}),
+		}),
+		customCardElement{tag: "div"},
+	})
+	want = []string{
+		"elements[0]: button is not allowed in the elements of a card",
+		"elements[1]: plain_text is not allowed in the elements of a card",
+		"elements[2]: repeat can only be used in a MessageCardTemplate",
+		"elements[4].columns[0].elements[0]: select_static is not allowed in a column",
+		"elements[5]: unknown div element test.customCardElement",
+	}
+	if err := misplaced.Validate(); !errors.As(err, &errs) || len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), err)
+	}
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Error(), want[i]) {
+			t.Errorf("error %d: want %q, got %q", i, want[i], err.Error())
+		}
+	}
+
+	// a repeat is only expanded by MessageCardTemplate.Render, wherever it is put in a card
+	repeat := feishuapi.NewMessageCardRepeat().WithItems("rows").WithElements([]feishuapi.MessageCardElement{plain("${item}")})
+	repeated := feishuapi.NewMessageCard().WithElements([]feishuapi.MessageCardElement{
+		feishuapi.NewMessageCardNote().WithElements([]feishuapi.MessageCardNoteElement{repeat}),
+		feishuapi.NewMessageCardAction().WithActions([]feishuapi.MessageCardActionElement{repeat}),
+	})
+	want = []string{
+		"elements[0].elements[0]: repeat can only be used in a MessageCardTemplate",
+		"elements[1].actions[0]: repeat can only be used in a MessageCardTemplate",
+	}
+	if err := repeated.Validate(); !errors.As(err, &errs) || len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), err)
+	}
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Error(), want[i]) {
+			t.Errorf("error %d: want %q, got %q", i, want[i], err.Error())
+		}
+	}
+
+	// a nil pointer of an element is reported instead of panicking
+	nilElements := feishuapi.NewMessageCard().WithElements([]feishuapi.MessageCardElement{
+		(*feishuapi.MessageCardDiv)(nil),
+		feishuapi.NewMessageCardDiv().WithText(plain("ok")).WithExtra((*feishuapi.MessageCardButton)(nil)),
+		feishuapi.NewMessageCardNote().WithElements([]feishuapi.MessageCardNoteElement{(*feishuapi.MessageCardPlainText)(nil)}),
+		feishuapi.NewMessageCardAction().WithActions([]feishuapi.MessageCardActionElement{(*feishuapi.MessageCardButton)(nil)}),
+		feishuapi.NewMessageCardColumnSet().WithFlexMode(feishuapi.FlexModeNone).WithColumns([]feishuapi.MessageCardColumn{
+			*feishuapi.NewMessageCardColumn().WithElements([]feishuapi.MessageCardElement{(*feishuapi.MessageCardColumnSet)(nil)}),
+		}),
+	})
+	want = []string{
+		"elements[0]: element is nil",
+		"elements[1].extra: element is nil",
+		"elements[2].elements[0]: element is nil",
+		"elements[3].actions[0]: element is nil",
+		"elements[4].columns[0].elements[0]: element is nil",
+	}
+	if err := nilElements.Validate(); !errors.As(err, &errs) || len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), err)
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("error %d: want %q, got %q", i, want[i], err.Error())
+		}
+	}
+
+	// the options of a select_person are optional
+	person := feishuapi.NewMessageCard().WithElements([]feishuapi.MessageCardElement{
+		feishuapi.NewMessageCardAction().WithActions([]feishuapi.MessageCardActionElement{
+			feishuapi.NewMessageCardSelectPerson().WithMessageCardSelectMenuBase(feishuapi.NewMessageCardSelectMenuBase().WithPlaceHolder(plain("who"))),
+		}),
+	})
+	if err := person.Validate(); err != nil {
+		t.Fatalf("select_person without options reported %v", err)
+	}
+}
+
+// an element which isn't built by this package
+type customCardElement struct {
+	tag string
+}
+
+func (e customCardElement) Tag() string {
+	return e.tag
+}
+
+func (e customCardElement) MarshalJSON() ([]byte, error) {
+	return []byte(`{"tag":"` + e.tag + `"}`), nil
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessageList(t *testing.T) {
+	var queries []map[string]string
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/im/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, map[string]string{
+			"container_id": q.Get("container_id"),
+			"start_time":   q.Get("start_time"),
+			"end_time":     q.Get("end_time"),
+			"page_token":   q.Get("page_token"),
+		})
+		if q.Get("page_token") == "" {
+			writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+				"has_more": true, "page_token": "p2",
+				"items": []any{
+					map[string]any{"message_id": "om_1", "msg_type": "text", "parent_id": "", "root_id": "",
+						"sender":   map[string]any{"id": "ou_1", "id_type": "open_id", "sender_type": "user"},
+						"body":     map[string]any{"content": `{"text":"@_user_1 hi"}`},
+						"mentions": []any{map[string]any{"key": "@_user_1", "id": "ou_2", "id_type": "open_id", "name": "bob"}}},
+					map[string]any{"message_id": "om_2", "msg_type": "post", "parent_id": "om_1", "root_id": "om_1",
+						"body": map[string]any{"content": `{"title":"t","content":[[{"tag":"text","text":"a","style":["bold"]},{"tag":"img","image_key":"img_1"}]]}`}},
+				},
+			}})
+			return
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+			"has_more": false,
+			"items": []any{
+				map[string]any{"message_id": "om_3", "msg_type": "image", "body": map[string]any{"content": `{"image_key":"img_2"}`}},
+				map[string]any{"message_id": "om_4", "msg_type": "interactive", "body": map[string]any{"content": `{"title":"card","elements":[[{"tag":"text","text":"x"}]]}`}},
+				map[string]any{"message_id": "om_5", "msg_type": "system", "body": map[string]any{"content": `{}`}},
+			},
+		}})
+	})
+
+	start := time.Unix(1700000000, 0)
+	it := cli.MessageList("oc_1", start, time.Time{})
+	var messages []*feishuapi.Message
+	for it.Next() {
+		messages = append(messages, it.Message())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 5 || len(queries) != 2 {
+		t.Fatalf("got %d messages in %d requests", len(messages), len(queries))
+	}
+	if queries[0]["container_id"] != "oc_1" || queries[0]["start_time"] != "1700000000" || queries[0]["end_time"] != "" || queries[1]["page_token"] != "p2" {
+		t.Fatalf("unexpected queries %v", queries)
+	}
+
+	text, ok := messages[0].ParsedContent.(*feishuapi.TextMessageContent)
+	if !ok || text.Text != "@_user_1 hi" || messages[0].Sender.Id != "ou_1" || messages[0].Mentions[0].Id.OpenId != "ou_2" {
+		t.Fatalf("unexpected text message %+v", messages[0])
+	}
+	post, ok := messages[1].ParsedContent.(*feishuapi.PostMessageContent)
+	if !ok || messages[1].ParentId != "om_1" || post.Content[0][0].Style[0] != "bold" || post.Content[0][1].ImageKey != "img_1" {
+		t.Fatalf("unexpected post message %+v", messages[1])
+	}
+	if image, ok := messages[2].ParsedContent.(*feishuapi.ImageMessageContent); !ok || image.ImageKey != "img_2" {
+		t.Fatalf("unexpected image message %+v", messages[2])
+	}
+	if card, ok := messages[3].ParsedContent.(*feishuapi.CardMessageContent); !ok || card.Title != "card" {
+		t.Fatalf("unexpected card message %+v", messages[3])
+	}
+	if messages[4].ParsedContent != nil {
+		t.Fatalf("system message should not be parsed, got %+v", messages[4].ParsedContent)
+	}
+}
+
+func TestMessageResourceDownload(t *testing.T) {
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/im/v1/messages/om_1/resources/file_1", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("type") != "file" {
+			t.Errorf("unexpected type %q", r.URL.Query().Get("type"))
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="report.pdf"`)
+		w.Write([]byte("%PDF"))
+	})
+	mux.HandleFunc("/open-apis/im/v1/messages/om_1/resources/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"code": 234003, "msg": "File not in msg."})
+	})
+
+	resource, err := cli.MessageResourceDownloadWithError("om_1", "file_1", feishuapi.MessageResourceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resource.FileName != "report.pdf" || resource.ContentType != "application/pdf" || string(resource.Data) != "%PDF" {
+		t.Fatalf("unexpected resource %+v", resource)
+	}
+
+	_, err = cli.MessageResourceDownloadWithError("om_1", "missing", feishuapi.MessageResourceFile)
+	var apiErr *feishuapi.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 234003 {
+		t.Fatalf("expected APIError 234003, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessagePost(t *testing.T) {
+	post := feishuapi.NewMessagePost().
+		WithContent(feishuapi.PostZhCN, feishuapi.NewMessagePostContent().
+			WithTitle("发布说明").
+			WithParagraph(
+				feishuapi.NewMessagePostText().WithText("v1.2 ").WithStyle(feishuapi.PostTextBold).Build(),
+				feishuapi.NewMessagePostLink().WithText("changelog").WithHref("https://example.com").Build(),
+				feishuapi.NewMessagePostAt().WithUserId("all").Build(),
+				feishuapi.NewMessagePostEmotion().WithEmojiType("SMILE").Build(),
+			).
+			WithParagraph(feishuapi.NewMessagePostCodeBlock().WithLanguage("GO").WithText("fmt.Println()").Build()).
+			Build()).
+		Build()
+
+	content, err := post.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]struct {
+		Title   string             `json:"title"`
+		Content [][]map[string]any `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(content), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	zh := decoded["zh_cn"]
+	if zh.Title != "发布说明" || len(zh.Content) != 2 || len(zh.Content[0]) != 4 {
+		t.Fatalf("unexpected post %s", content)
+	}
+	if zh.Content[0][1]["tag"] != "a" || zh.Content[0][1]["href"] != "https://example.com" || zh.Content[1][0]["tag"] != "code_block" {
+		t.Fatalf("unexpected elements %s", content)
+	}
+
+	if _, err := feishuapi.NewMessagePost().String(); err == nil {
+		t.Fatal("expected an error for an empty post")
+	}
+	bad := feishuapi.NewMessagePost().WithContent(feishuapi.PostEnUS, feishuapi.NewMessagePostContent().
+		WithParagraph(feishuapi.NewMessagePostLink().WithText("no href").Build()).Build())
+	if _, err := bad.String(); err == nil {
+		t.Fatal("expected an error for a link without href")
+	}
+}
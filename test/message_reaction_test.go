@@ -0,0 +1,92 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessageReactionsPinsAndReadUsers(t *testing.T) {
+	var calls []string
+	cli, mux := newMockClient(t)
+	handle := func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /open-apis/im/v1/messages/om_1/reactions":
+			emoji := body["reaction_type"].(map[string]any)["emoji_type"]
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{
+				"reaction_id": "r_1", "action_time": "1700000000000",
+				"operator":      map[string]any{"operator_id": "cli_reaction", "operator_type": "app"},
+				"reaction_type": map[string]any{"emoji_type": emoji},
+			}})
+		case "GET /open-apis/im/v1/messages/om_1/reactions":
+			if r.URL.Query().Get("reaction_type") != "DONE" {
+				t.Errorf("unexpected reaction_type %q", r.URL.Query().Get("reaction_type"))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"has_more": false, "items": []any{
+				map[string]any{"reaction_id": "r_2", "operator": map[string]any{"operator_id": "ou_1", "operator_type": "user"}, "reaction_type": map[string]any{"emoji_type": "DONE"}},
+			}}})
+		case "DELETE /open-apis/im/v1/messages/om_1/reactions/r_1", "DELETE /open-apis/im/v1/pins/om_1":
+			json.NewEncoder(w).Encode(map[string]any{"code": 0})
+		case "POST /open-apis/im/v1/pins":
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"pin": map[string]any{
+				"message_id": body["message_id"], "chat_id": "oc_1", "operator_id": "cli_reaction", "operator_id_type": "app", "create_time": "1700000000000",
+			}}})
+		case "GET /open-apis/im/v1/messages/om_1/read_users":
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"has_more": false, "items": []any{
+				map[string]any{"user_id_type": "open_id", "user_id": "ou_1", "timestamp": "1700000001000"},
+			}}})
+		case "GET /open-apis/im/v1/chats/oc_1/members":
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"has_more": false, "items": []any{
+				map[string]any{"member_id": "ou_1", "name": "alice"},
+				map[string]any{"member_id": "ou_2", "name": "bob"},
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+	for _, path := range []string{"/open-apis/im/v1/messages/om_1/", "/open-apis/im/v1/pins", "/open-apis/im/v1/pins/", "/open-apis/im/v1/chats/oc_1/members"} {
+		mux.HandleFunc(path, handle)
+	}
+
+	reaction, err := cli.MessageReactionAddWithError("om_1", "THUMBSUP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reaction.ReactionId != "r_1" || reaction.EmojiType != "THUMBSUP" || reaction.OperatorType != "app" || reaction.ActionTime.Unix() != 1700000000 {
+		t.Fatalf("unexpected reaction %+v", reaction)
+	}
+	reactions, err := cli.MessageReactionListWithError("om_1", "DONE")
+	if err != nil || len(reactions) != 1 || reactions[0].OperatorId != "ou_1" {
+		t.Fatalf("unexpected reactions %+v, %v", reactions, err)
+	}
+	if err := cli.MessageReactionDeleteWithError("om_1", reaction.ReactionId); err != nil {
+		t.Fatal(err)
+	}
+
+	pin, err := cli.MessagePinWithError("om_1")
+	if err != nil || pin.MessageId != "om_1" || pin.ChatId != "oc_1" {
+		t.Fatalf("unexpected pin %+v, %v", pin, err)
+	}
+	if err := cli.MessageUnpinWithError("om_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	readUsers, err := cli.MessageReadUsersWithError("om_1", feishuapi.OpenId)
+	if err != nil || len(readUsers) != 1 || readUsers[0].UserId != "ou_1" || readUsers[0].ReadTime.Unix() != 1700000001 {
+		t.Fatalf("unexpected read users %+v, %v", readUsers, err)
+	}
+	unread, err := cli.MessageUnreadMembersWithError("om_1", "oc_1", feishuapi.OpenId)
+	if err != nil || len(unread) != 1 || unread[0].MemberId != "ou_2" {
+		t.Fatalf("unexpected unread members %+v, %v", unread, err)
+	}
+
+	if len(calls) != 8 {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+}
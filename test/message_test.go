@@ -0,0 +1,67 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessageReplyGetRecall(t *testing.T) {
+	var replyBody map[string]any
+	recalled := ""
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/im/v1/messages/om_1/reply", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&replyBody)
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+			"message_id": "om_2", "root_id": "om_1", "parent_id": "om_1", "thread_id": "omt_1", "msg_type": "text",
+			"create_time": "1700000000000", "chat_id": "oc_1",
+			"sender": map[string]any{"id": "cli_msg", "id_type": "app_id", "sender_type": "app"},
+			"body":   map[string]any{"content": `{"text":"ack"}`},
+		}})
+	})
+	mux.HandleFunc("/open-apis/im/v1/messages/om_merge", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"items": []any{
+			map[string]any{"message_id": "om_merge", "msg_type": "merge_forward", "body": map[string]any{"content": "Merged and Forwarded Message"}},
+			map[string]any{"message_id": "om_a", "msg_type": "text", "upper_message_id": "om_merge", "mentions": []any{
+				map[string]any{"key": "@_user_1", "id": "ou_1", "id_type": "open_id", "name": "u"},
+			}},
+		}}})
+	})
+	mux.HandleFunc("/open-apis/im/v1/messages/om_2", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			http.NotFound(w, r)
+			return
+		}
+		recalled = "om_2"
+		writeJSON(w, map[string]any{"code": 0})
+	})
+
+	reply, err := cli.MessageReplyWithError("om_1", feishuapi.Text, "ack", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replyBody["reply_in_thread"] != true || replyBody["content"] != `{"text":"ack"}` {
+		t.Fatalf("unexpected reply body %v", replyBody)
+	}
+	if reply.MessageId != "om_2" || reply.ThreadId != "omt_1" || reply.Sender.SenderType != "app" || reply.Content != `{"text":"ack"}` || reply.CreateTime.Unix() != 1700000000 {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+
+	merged, err := cli.MessageGetWithError("om_merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.MsgType != "merge_forward" || len(merged.Children) != 1 || merged.Children[0].Mentions[0].Id.OpenId != "ou_1" {
+		t.Fatalf("unexpected merge forward message %+v", merged)
+	}
+
+	if err := cli.MessageRecallWithError(reply.MessageId); err != nil || recalled != "om_2" {
+		t.Fatalf("recall: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMessageUrgentAndEphemeralCard(t *testing.T) {
+	bodies := make(map[string]map[string]any)
+	cli, mux := newMockClient(t)
+	// record the body of every request by its method and path
+	record := func(method string, handle func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != method {
+				http.NotFound(w, r)
+				return
+			}
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			bodies[r.Method+" "+r.URL.Path] = body
+			handle(w, r)
+		}
+	}
+	mux.HandleFunc("/open-apis/im/v1/messages/om_1/urgent_phone", record("PATCH", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("user_id_type") != "open_id" {
+			t.Errorf("unexpected user_id_type %q", r.URL.Query().Get("user_id_type"))
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"invalid_user_id_list": []any{"ou_gone"}}})
+	}))
+	mux.HandleFunc("/open-apis/ephemeral/v1/send", record("POST", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"message_id": "om_eph"}})
+	}))
+	mux.HandleFunc("/open-apis/ephemeral/v1/delete", record("POST", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0})
+	}))
+
+	invalid, err := cli.MessageUrgentWithError("om_1", feishuapi.UrgentPhone, feishuapi.OpenId, []string{"ou_1", "ou_gone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invalid) != 1 || invalid[0] != "ou_gone" {
+		t.Fatalf("unexpected invalid users %v", invalid)
+	}
+	if ids := bodies["PATCH /open-apis/im/v1/messages/om_1/urgent_phone"]["user_id_list"].([]any); len(ids) != 2 {
+		t.Fatalf("unexpected user_id_list %v", ids)
+	}
+
+	card := feishuapi.NewMessageCard().WithHeader(feishuapi.NewMessageCardHeader().WithTitle(feishuapi.NewMessageCardPlainText().WithContent("Confirm")))
+	messageId, err := cli.MessageSendEphemeralCardWithError("oc_1", feishuapi.UserOpenId, "ou_1", card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := bodies["POST /open-apis/ephemeral/v1/send"]
+	if messageId != "om_eph" || sent["chat_id"] != "oc_1" || sent["open_id"] != "ou_1" || sent["msg_type"] != "interactive" {
+		t.Fatalf("unexpected ephemeral request %v", sent)
+	}
+	if _, ok := sent["card"].(map[string]any)["header"]; !ok {
+		t.Fatalf("card should be sent as an object, got %v", sent["card"])
+	}
+
+	if _, err := cli.MessageSendEphemeralCardWithError("oc_1", feishuapi.GroupChatId, "oc_2", card); err == nil {
+		t.Fatal("chat_id should be rejected as the receiver")
+	}
+
+	if err := cli.MessageDeleteEphemeralCardWithError(messageId); err != nil {
+		t.Fatal(err)
+	}
+	if bodies["POST /open-apis/ephemeral/v1/delete"]["message_id"] != "om_eph" {
+		t.Fatalf("unexpected delete request %v", bodies["POST /open-apis/ephemeral/v1/delete"])
+	}
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace-Id") != "trace" {
+			t.Errorf("header middleware not applied to %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"code": 0, "tenant_access_token": "t-mw", "expire": 7200, "data": map[string]any{}})
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) feishuapi.Middleware {
+		return func(next feishuapi.RoundTripFunc) feishuapi.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next(req)
+			}
+		}
+	}
+	observed := 0
+
+	var cli feishuapi.AppClient
+	cli.Conf.AppId = "cli_mw"
+	cli.Conf.AppSecret = "secret"
+	cli.Conf.BaseURL = server.URL
+	cli.HTTPClient = server.Client()
+	cli.Middlewares = []feishuapi.Middleware{
+		trace("outer"),
+		feishuapi.HeaderMiddleware(map[string]string{"X-Trace-Id": "trace"}),
+		feishuapi.LatencyMiddleware(func(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+			observed++
+		}),
+		trace("inner"),
+	}
+
+	if _, err := cli.RequestWithError("get", "open-apis/mock", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// the tenant_access_token request and the api request
+	if observed != 2 || len(order) != 4 || order[0] != "outer" || order[1] != "inner" {
+		t.Fatalf("unexpected middleware calls: observed %d, order %v", observed, order)
+	}
+}
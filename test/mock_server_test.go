@@ -0,0 +1,36 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+const mockTenantAccessToken = "t-mock"
+
+// Start a mock open platform which hands out mockTenantAccessToken, and return a client which sends requests to it.
+// The test registers its own endpoints on the mux, the requests to the others are answered with 404.
+// The AppId is unique to the test, so the client doesn't share the cached token of another test
+func newMockClient(t *testing.T) (feishuapi.AppClient, *http.ServeMux) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/open-apis/auth/v3/tenant_access_token/internal", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0, "tenant_access_token": mockTenantAccessToken, "expire": 7200})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	var cli feishuapi.AppClient
+	cli.Conf.AppId = "cli_" + strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	cli.Conf.AppSecret = "secret"
+	cli.Conf.BaseURL = server.URL
+	return cli, mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,28 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+// Feishu returns null for some empty fields, which are treated like the missing ones
+func TestNullFieldsAreDefaults(t *testing.T) {
+	employee := feishuapi.NewEmployeeInfo(map[string]any{
+		"user_id": "u1",
+		"system_fields": map[string]any{
+			"name":          "alice",
+			"department_id": nil,
+			"employee_type": float64(1),
+			"status":        float64(2),
+		},
+	})
+	if employee.DepartmentId != "" || employee.Name != "alice" {
+		t.Fatalf("unexpected employee %+v", employee)
+	}
+
+	user := feishuapi.NewLoginUserInfo(map[string]any{"open_id": "ou_1", "email": nil, "mobile": nil})
+	if user.OpenId != "ou_1" || user.Email != "" || user.Mobile != "" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+}
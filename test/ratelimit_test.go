@@ -0,0 +1,40 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestRateLimiterEndpointLimit(t *testing.T) {
+	limiter := feishuapi.NewRateLimiter(0, 0).
+		WithEndpointLimit("get", "open-apis/bitable/v1/apps", 20, 1)
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "GET", "open-apis/bitable/v1/apps/app/tables/tbl/records"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("3 requests at 20/s with burst 1 should take about 100ms, took %v", elapsed)
+	}
+
+	// requests which match no endpoint are not limited
+	start = time.Now()
+	for i := 0; i < 10; i++ {
+		limiter.Wait(ctx, "GET", "open-apis/im/v1/chats")
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("unlimited requests took %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(cancelled, "GET", "open-apis/bitable/v1/apps/app"); err == nil {
+		t.Error("Wait should return the context error when it has to block")
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+// Serve open-apis/mock with fail for the first failures requests and a success after them
+func newRetryClient(t *testing.T, failures int32, fail func(w http.ResponseWriter)) (feishuapi.AppClient, *int32) {
+	var calls int32
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/mock", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			fail(w)
+			return
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"ok": true}})
+	})
+	cli.RetryPolicy = feishuapi.DefaultRetryPolicy().
+		WithBaseDelay(time.Millisecond).
+		WithMaxDelay(time.Second * 5)
+	return cli, &calls
+}
+
+func TestRetryTransientResponses(t *testing.T) {
+	cases := []struct {
+		name string
+		fail func(w http.ResponseWriter)
+	}{
+		{"http 429", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}},
+		{"rate limit code", func(w http.ResponseWriter) {
+			json.NewEncoder(w).Encode(map[string]any{"code": feishuapi.CodeRateLimited, "msg": "too many requests"})
+		}},
+		{"http 503", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, calls := newRetryClient(t, 2, tc.fail)
+			resp, err := cli.RequestWithContext(context.Background(), "get", "open-apis/mock", nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if atomic.LoadInt32(calls) != 3 || resp["ok"] != true {
+				t.Fatalf("expected 3 attempts, got %d, resp %v", atomic.LoadInt32(calls), resp)
+			}
+		})
+	}
+}
+
+func TestRetryRateLimitReset(t *testing.T) {
+	cli, calls := newRetryClient(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("x-ogw-ratelimit-reset", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	start := time.Now()
+	if _, err := cli.RequestWithError("get", "open-apis/mock", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond || atomic.LoadInt32(calls) != 2 {
+		t.Fatalf("the reset of 1s should be waited, waited %v with %d attempts", elapsed, atomic.LoadInt32(calls))
+	}
+
+	// a reset of 0 falls back to the exponential backoff instead of being ignored
+	cli, calls = newRetryClient(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("x-ogw-ratelimit-reset", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	if _, err := cli.RequestWithError("get", "open-apis/mock", nil, nil, nil); err != nil || atomic.LoadInt32(calls) != 2 {
+		t.Fatalf("expected 2 attempts, got %d: %v", atomic.LoadInt32(calls), err)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	cli, calls := newRetryClient(t, 1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	if _, err := cli.RequestWithError("post", "open-apis/mock", nil, nil, map[string]any{}); err == nil {
+		t.Fatal("POST should fail without retrying")
+	}
+	if atomic.LoadInt32(calls) != 1 {
+		t.Fatalf("POST is not retried by default, got %d attempts", atomic.LoadInt32(calls))
+	}
+
+	cli.RetryPolicy.WithRetryNonIdempotent(true)
+	atomic.StoreInt32(calls, 0)
+	if _, err := cli.RequestWithError("post", "open-apis/mock", nil, nil, map[string]any{}); err != nil || atomic.LoadInt32(calls) != 2 {
+		t.Fatalf("POST should be retried when allowed, got %d attempts: %v", atomic.LoadInt32(calls), err)
+	}
+}
+
+func TestRetryOnlyTransportErrors(t *testing.T) {
+	// the connection is dropped before the first response
+	cli, calls := newRetryClient(t, 1, func(w http.ResponseWriter) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	})
+	if _, err := cli.RequestWithError("get", "open-apis/mock", nil, nil, nil); err != nil || atomic.LoadInt32(calls) != 2 {
+		t.Fatalf("a dropped connection should be retried, got %d attempts: %v", atomic.LoadInt32(calls), err)
+	}
+
+	// a body which can't be marshaled fails the same way every time
+	cli.RetryPolicy.WithBaseDelay(time.Second)
+	atomic.StoreInt32(calls, 0)
+	start := time.Now()
+	_, err := cli.RequestWithError("put", "open-apis/mock", nil, nil, map[string]any{"c": make(chan int)})
+	if err == nil || atomic.LoadInt32(calls) != 0 {
+		t.Fatalf("expected a marshal error before sending, got %d attempts: %v", atomic.LoadInt32(calls), err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("a marshal error should not be retried, took %v", elapsed)
+	}
+}
@@ -0,0 +1,187 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestTenantTokenManagerDeduplicatesRefresh(t *testing.T) {
+	var fetches int32
+	manager := feishuapi.NewTenantTokenManager(func(ctx context.Context) (string, time.Duration, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		time.Sleep(20 * time.Millisecond)
+		return "t-" + strconv.Itoa(int(n)), time.Hour, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := manager.TenantAccessToken(context.Background())
+			if err != nil || token != "t-1" {
+				t.Errorf("got %q, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Fatalf("expected 1 fetch, got %d", fetches)
+	}
+
+	manager.InvalidateTenantAccessToken("t-1")
+	token, _ := manager.TenantAccessToken(context.Background())
+	if token != "t-2" {
+		t.Fatalf("expected a new token after invalidation, got %q", token)
+	}
+}
+
+func TestTenantTokenManagerRefreshAhead(t *testing.T) {
+	var fetches int32
+	fail := int32(0)
+	manager := feishuapi.NewTenantTokenManager(func(ctx context.Context) (string, time.Duration, error) {
+		if atomic.LoadInt32(&fail) == 1 {
+			return "", 0, errors.New("auth endpoint down")
+		}
+		n := atomic.AddInt32(&fetches, 1)
+		return "t-" + strconv.Itoa(int(n)), 200 * time.Millisecond, nil
+	}).WithRefreshAhead(150 * time.Millisecond)
+
+	ctx := context.Background()
+	if token, _ := manager.TenantAccessToken(ctx); token != "t-1" {
+		t.Fatalf("got %q", token)
+	}
+	if token, _ := manager.TenantAccessToken(ctx); token != "t-1" {
+		t.Fatalf("token should be cached, got %q", token)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if token, _ := manager.TenantAccessToken(ctx); token != "t-2" {
+		t.Fatalf("token should be refreshed ahead of expire, got %q", token)
+	}
+
+	// the old token is used while it is valid, even if the refresh fails
+	atomic.StoreInt32(&fail, 1)
+	time.Sleep(80 * time.Millisecond)
+	if token, err := manager.TenantAccessToken(ctx); token != "t-2" || err != nil {
+		t.Fatalf("got %q, %v", token, err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if _, err := manager.TenantAccessToken(ctx); err == nil {
+		t.Fatal("expected an error once the token expired")
+	}
+}
+
+func TestInvalidTenantTokenIsRefetchedOnce(t *testing.T) {
+	var fetches, calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/open-apis/auth/v3/tenant_access_token/internal" {
+			n := atomic.AddInt32(&fetches, 1)
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "tenant_access_token": "t-" + strconv.Itoa(int(n)), "expire": 7200})
+			return
+		}
+		// the first token is revoked before it expires
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			if r.Header.Get("Authorization") != "Bearer t-1" {
+				t.Errorf("first request sent with %q", r.Header.Get("Authorization"))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"code": feishuapi.CodeInvalidTenantToken, "msg": "Invalid access token for authorization"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t-2" {
+			t.Errorf("retried request sent with %q", r.Header.Get("Authorization"))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{"ok": true}})
+	}))
+	defer server.Close()
+
+	var cli feishuapi.AppClient
+	cli.Conf.AppId = "cli_invalid_token"
+	cli.Conf.AppSecret = "secret"
+	cli.Conf.BaseURL = server.URL
+	cli.HTTPClient = server.Client()
+
+	resp, err := cli.RequestWithError("get", "open-apis/mock", nil, nil, nil)
+	if err != nil || resp["ok"] != true {
+		t.Fatalf("got %v, %v", resp, err)
+	}
+	if fetches != 2 || calls != 2 {
+		t.Fatalf("expected 1 refetch and 1 retry, got %d fetches and %d calls", fetches, calls)
+	}
+}
+
+// a TokenStore which can't be used as a map key
+type unhashableTokenStore struct {
+	feishuapi.TokenStore
+	tags []string
+}
+
+func TestSharedTokenManagerUsesCallingClient(t *testing.T) {
+	var fetches, revoked int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/open-apis/auth/v3/tenant_access_token/internal" {
+			n := atomic.AddInt32(&fetches, 1)
+			json.NewEncoder(w).Encode(map[string]any{"code": 0, "tenant_access_token": "t-" + strconv.Itoa(int(n)), "expire": 7200})
+			return
+		}
+		if atomic.LoadInt32(&revoked) == 1 && r.Header.Get("Authorization") == "Bearer t-1" {
+			json.NewEncoder(w).Encode(map[string]any{"code": feishuapi.CodeInvalidTenantToken, "msg": "Invalid access token for authorization"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"code": 0, "data": map[string]any{}})
+	}))
+	defer server.Close()
+
+	var fetchedBy []string
+	client := func(name string) feishuapi.AppClient {
+		var cli feishuapi.AppClient
+		cli.Conf.AppId = "cli_shared_manager"
+		cli.Conf.AppSecret = "secret"
+		cli.Conf.BaseURL = server.URL
+		// every client has its own http.Client and store, which are not shared with the others
+		cli.HTTPClient = &http.Client{Transport: server.Client().Transport}
+		cli.TokenStore = unhashableTokenStore{TokenStore: feishuapi.NewMemoryTokenStore()}
+		cli.Middlewares = []feishuapi.Middleware{func(next feishuapi.RoundTripFunc) feishuapi.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/open-apis/auth/v3/tenant_access_token/internal" {
+					fetchedBy = append(fetchedBy, name)
+				}
+				return next(req)
+			}
+		}}
+		return cli
+	}
+	first, second := client("first"), client("second")
+
+	if _, err := first.RequestWithError("get", "open-apis/mock", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.RequestWithError("get", "open-apis/mock", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Fatalf("the clients with the same credentials should share the token, got %d fetches", fetches)
+	}
+
+	// the token is fetched again through the client which needs it, not the one which created the manager
+	atomic.StoreInt32(&revoked, 1)
+	if _, err := second.RequestWithError("get", "open-apis/mock", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 || len(fetchedBy) != 2 || fetchedBy[0] != "first" || fetchedBy[1] != "second" {
+		t.Fatalf("unexpected fetches %d by %v", fetches, fetchedBy)
+	}
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestTokenManagersShareStore(t *testing.T) {
+	store := feishuapi.NewMemoryTokenStore()
+	var fetches int32
+	fetch := func(ctx context.Context) (string, time.Duration, error) {
+		atomic.AddInt32(&fetches, 1)
+		return "shared", time.Hour, nil
+	}
+
+	first := feishuapi.NewTenantTokenManager(fetch).WithStore(store, "tenant")
+	second := feishuapi.NewTenantTokenManager(fetch).WithStore(store, "tenant")
+
+	for _, manager := range []*feishuapi.TenantTokenManager{first, second} {
+		token, err := manager.TenantAccessToken(context.Background())
+		if err != nil || token != "shared" {
+			t.Fatalf("got %q, %v", token, err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("expected the second manager to reuse the stored token, got %d fetches", fetches)
+	}
+
+	second.InvalidateTenantAccessToken("shared")
+	if _, _, err := store.Get(context.Background(), "tenant"); !errors.Is(err, feishuapi.ErrTokenNotFound) {
+		t.Fatalf("invalidated token should be removed from store, got %v", err)
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	store := feishuapi.NewFileTokenStore(path)
+
+	if _, _, err := store.Get(ctx, "k"); !errors.Is(err, feishuapi.ErrTokenNotFound) {
+		t.Fatalf("expected ErrTokenNotFound, got %v", err)
+	}
+	if swapped, err := store.CompareAndSwap(ctx, "k", "", "v1", time.Minute); !swapped || err != nil {
+		t.Fatalf("swap on absent key: %v, %v", swapped, err)
+	}
+	if swapped, _ := store.CompareAndSwap(ctx, "k", "", "v2", time.Minute); swapped {
+		t.Fatal("swap should fail when the old value doesn't match")
+	}
+
+	// another process sees the same file
+	other := feishuapi.NewFileTokenStore(path)
+	value, expireAt, err := other.Get(ctx, "k")
+	if err != nil || value != "v1" || time.Until(expireAt) <= 0 {
+		t.Fatalf("got %q, %v, %v", value, expireAt, err)
+	}
+
+	if err := other.Set(ctx, "k", "v3", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Get(ctx, "k"); !errors.Is(err, feishuapi.ErrTokenNotFound) {
+		t.Fatalf("expired value should not be returned, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestUploadAndSendImage(t *testing.T) {
+	var content string
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/im/v1/images", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+			return
+		}
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, _ := ioutil.ReadAll(file)
+		if r.FormValue("image_type") != "message" || string(data) != "png-bytes" {
+			t.Errorf("unexpected form %v %q", r.MultipartForm.Value, data)
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"image_key": "img_1"}})
+	})
+	mux.HandleFunc("/open-apis/im/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		if r.FormValue("file_type") != "pdf" || r.FormValue("file_name") != "report.pdf" {
+			t.Errorf("unexpected form %v", r.MultipartForm.Value)
+		}
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"file_key": "file_1"}})
+	})
+	mux.HandleFunc("/open-apis/im/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		content = body["content"]
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"message_id": "om_1"}})
+	})
+
+	imageKey, err := cli.ImageUploadWithError(strings.NewReader("png-bytes"))
+	if err != nil || imageKey != "img_1" {
+		t.Fatalf("got %q, %v", imageKey, err)
+	}
+	if _, err := cli.MessageSendWithError(feishuapi.GroupChatId, "oc_1", feishuapi.Image, imageKey); err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"image_key":"img_1"}` {
+		t.Fatalf("unexpected content %s", content)
+	}
+
+	fileKey, err := cli.FileUploadWithError("report.pdf", feishuapi.UploadFilePdf, strings.NewReader("pdf-bytes"))
+	if err != nil || fileKey != "file_1" {
+		t.Fatalf("got %q, %v", fileKey, err)
+	}
+	if _, err := cli.MessageSendWithError(feishuapi.GroupChatId, "oc_1", feishuapi.Media, `{"file_key":"file_1","image_key":"img_1"}`); err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"file_key":"file_1","image_key":"img_1"}` {
+		t.Fatalf("unexpected content %s", content)
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/YasyaKarasu/feishuapi"
+)
+
+func TestUserTokenSourceLoadsFromStore(t *testing.T) {
+	ctx := context.Background()
+	store := feishuapi.NewMemoryTokenStore()
+	var cli feishuapi.AppClient
+
+	if _, err := cli.LoadUserTokenSource(ctx, "ou_1", store); !errors.Is(err, feishuapi.ErrTokenNotFound) {
+		t.Fatalf("expected ErrTokenNotFound, got %v", err)
+	}
+
+	token := &feishuapi.UserAccessToken{
+		Access_token:    "u-access",
+		Refresh_token:   "ur-refresh",
+		Open_id:         "ou_1",
+		ExpireAt:        time.Now().Add(time.Hour),
+		RefreshExpireAt: time.Now().Add(time.Hour * 24),
+	}
+	if _, err := cli.NewUserTokenSource(ctx, token, store); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := cli.LoadUserTokenSource(ctx, "ou_1", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, err := source.UserAccessToken(ctx)
+	if err != nil || access != "u-access" {
+		t.Fatalf("got %q, %v", access, err)
+	}
+}
+
+func TestStaticUserTokenTrimsBearer(t *testing.T) {
+	for _, raw := range []string{"u-access", "Bearer u-access"} {
+		token, err := feishuapi.StaticUserToken(raw).UserAccessToken(context.Background())
+		if err != nil || token != "u-access" {
+			t.Fatalf("%q: got %q, %v", raw, token, err)
+		}
+	}
+}
+
+func TestUserAccessTokenMissingFields(t *testing.T) {
+	cli, mux := newMockClient(t)
+	mux.HandleFunc("/open-apis/authen/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"open_id": "ou_1"}})
+	})
+	mux.HandleFunc("/open-apis/authen/v1/refresh_access_token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{
+			"access_token":       "u-refreshed",
+			"refresh_token":      "ur-refreshed",
+			"expires_in":         7200,
+			"refresh_expires_in": 2592000,
+		}})
+	})
+	mux.HandleFunc("/open-apis/mina/v2/tokenLoginValidate", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"code": 0, "data": map[string]any{"session_key": "s"}})
+	})
+
+	if token, err := cli.GetUserAccessTokenWithError("code"); err == nil {
+		t.Fatalf("expected an error for a response without access_token, got %+v", token)
+	}
+	if session, err := cli.GetLoginSessionWithError("code"); err == nil {
+		t.Fatalf("expected an error for a response without open_id, got %+v", session)
+	}
+	if _, err := cli.NewUserTokenSource(context.Background(), nil, nil); err == nil {
+		t.Fatal("expected an error for a nil token")
+	}
+
+	// the refreshed token without open_id keeps the open_id of the source
+	source, err := cli.NewUserTokenSource(context.Background(), &feishuapi.UserAccessToken{
+		Access_token:    "u-expired",
+		Refresh_token:   "ur-old",
+		Open_id:         "ou_1",
+		ExpireAt:        time.Now(),
+		RefreshExpireAt: time.Now().Add(time.Hour),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := source.Token(context.Background())
+	if err != nil || token.Access_token != "u-refreshed" || token.Open_id != "ou_1" {
+		t.Fatalf("got %+v, %v", token, err)
+	}
+}